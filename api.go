@@ -0,0 +1,316 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/scottyloveless/gator/internal/database"
+)
+
+type apiUser struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Name      string    `json:"name"`
+	APIKey    string    `json:"api_key,omitempty"`
+}
+
+type apiFeed struct {
+	ID            int32      `json:"id"`
+	Name          string     `json:"name"`
+	URL           string     `json:"url"`
+	SiteURL       string     `json:"site_url,omitempty"`
+	AddedBy       string     `json:"added_by,omitempty"`
+	LastFetchedAt *time.Time `json:"last_fetched_at"`
+}
+
+type apiFollow struct {
+	FeedID   int32  `json:"feed_id"`
+	FeedName string `json:"feed_name"`
+	FeedURL  string `json:"feed_url"`
+	Folder   string `json:"folder,omitempty"`
+}
+
+type apiPost struct {
+	ID          int32     `json:"id"`
+	FeedID      int32     `json:"feed_id"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	Description string    `json:"description"`
+	Excerpt     string    `json:"excerpt"`
+	PublishedAt time.Time `json:"published_at"`
+	Highlight   string    `json:"highlight,omitempty"`
+}
+
+func toAPIFeed(feed database.ListFeedsRow) apiFeed {
+	item := apiFeed{
+		ID:      feed.ID,
+		Name:    feed.Name,
+		URL:     feed.Url,
+		SiteURL: feed.SiteUrl.String,
+		AddedBy: feed.Username.String,
+	}
+	if feed.LastFetchedAt.Valid {
+		item.LastFetchedAt = &feed.LastFetchedAt.Time
+	}
+	return item
+}
+
+func toAPIFollow(follow database.GetFeedFollowsForUserRow) apiFollow {
+	return apiFollow{
+		FeedID:   follow.FeedID,
+		FeedName: follow.FeedName,
+		FeedURL:  follow.FeedUrl,
+		Folder:   follow.Folder.String,
+	}
+}
+
+func toAPIPost(post database.Post) apiPost {
+	return apiPost{
+		ID:          post.ID,
+		FeedID:      post.FeedID,
+		Title:       post.Title,
+		URL:         post.Url,
+		Description: safeDescription(post.SafeHtml, post.Description),
+		Excerpt:     postExcerpt(post, storedExcerptLength),
+		PublishedAt: post.PublishedAt,
+	}
+}
+
+// handlerAPICreateUser needs an existing user's key, so an open port doesn't
+// hand out accounts. The new key is only ever shown in this response.
+func (s *state) handlerAPICreateUser(w http.ResponseWriter, r *http.Request, _ database.User) {
+	var params struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil || params.Name == "" {
+		respondWithError(w, http.StatusBadRequest, "request body must be {\"name\": \"...\"}")
+		return
+	}
+
+	if _, err := s.db.GetUser(r.Context(), params.Name); err == nil {
+		respondWithError(w, http.StatusConflict, "user already exists")
+		return
+	}
+
+	user, err := s.db.CreateUser(r.Context(), database.CreateUserParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Name:      params.Name,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	key, err := generateAPIKey()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	user, err = s.db.SetUserAPIKey(r.Context(), database.SetUserAPIKeyParams{
+		ID:         user.ID,
+		ApiKeyHash: sql.NullString{String: hashAPIKey(key), Valid: true},
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, apiUser{
+		ID:        user.ID,
+		CreatedAt: user.CreatedAt,
+		Name:      user.Name,
+		APIKey:    key,
+	})
+}
+
+func (s *state) handlerAPIUsers(w http.ResponseWriter, r *http.Request, user database.User) {
+	users, err := s.db.GetUsers(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp := make([]apiUser, 0, len(users))
+	for _, u := range users {
+		resp = append(resp, apiUser{ID: u.ID, CreatedAt: u.CreatedAt, Name: u.Name})
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+func (s *state) handlerAPIFeeds(w http.ResponseWriter, r *http.Request, user database.User) {
+	feeds, err := s.db.ListFeeds(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp := make([]apiFeed, 0, len(feeds))
+	for _, feed := range feeds {
+		resp = append(resp, toAPIFeed(feed))
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+func (s *state) handlerAPICreateFeed(w http.ResponseWriter, r *http.Request, user database.User) {
+	var params struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil || params.Name == "" || params.URL == "" {
+		respondWithError(w, http.StatusBadRequest, "request body must be {\"name\": \"...\", \"url\": \"...\"}")
+		return
+	}
+
+	feed, err := s.db.CreateFeed(r.Context(), database.CreateFeedParams{
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Name:      params.Name,
+		Url:       params.URL,
+		UserID:    user.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if _, err := s.db.CreateFeedFollow(r.Context(), database.CreateFeedFollowParams{
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		FeedID:    feed.ID,
+	}); err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, apiFeed{ID: feed.ID, Name: feed.Name, URL: feed.Url, AddedBy: user.Name})
+}
+
+func (s *state) handlerAPIFollows(w http.ResponseWriter, r *http.Request, user database.User) {
+	follows, err := s.db.GetFeedFollowsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp := make([]apiFollow, 0, len(follows))
+	for _, follow := range follows {
+		resp = append(resp, toAPIFollow(follow))
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+func (s *state) handlerAPIFollow(w http.ResponseWriter, r *http.Request, user database.User) {
+	var params struct {
+		FeedURL string `json:"feed_url"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil || params.FeedURL == "" {
+		respondWithError(w, http.StatusBadRequest, "request body must be {\"feed_url\": \"...\"}")
+		return
+	}
+
+	feed, err := s.db.GetFeedByURL(r.Context(), params.FeedURL)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "no feed found at that url")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	follow, err := s.db.CreateFeedFollow(r.Context(), database.CreateFeedFollowParams{
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		FeedID:    feed.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, apiFollow{FeedID: follow.FeedID, FeedName: follow.FeedName, FeedURL: feed.Url})
+}
+
+func (s *state) handlerAPIUnfollow(w http.ResponseWriter, r *http.Request, user database.User) {
+	feedURL := r.URL.Query().Get("feed_url")
+	if feedURL == "" {
+		respondWithError(w, http.StatusBadRequest, "feed_url query parameter is required")
+		return
+	}
+
+	if err := s.db.Unfollow(r.Context(), database.UnfollowParams{Name: user.Name, Url: feedURL}); err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *state) handlerAPIPosts(w http.ResponseWriter, r *http.Request, user database.User) {
+	limit := 20
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			respondWithError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+
+	var posts []database.Post
+	var err error
+	if r.URL.Query().Get("unread") == "true" {
+		posts, err = s.db.GetUnreadPostsForUser(r.Context(), database.GetUnreadPostsForUserParams{Name: user.Name, Limit: int32(limit)})
+	} else {
+		posts, err = s.db.GetPostsForUser(r.Context(), database.GetPostsForUserParams{Name: user.Name, Limit: int32(limit)})
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp := make([]apiPost, 0, len(posts))
+	for _, post := range posts {
+		resp = append(resp, toAPIPost(post))
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+func (s *state) handlerAPIMarkRead(w http.ResponseWriter, r *http.Request, user database.User) {
+	postID, err := strconv.ParseInt(r.PathValue("postID"), 10, 32)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid post id")
+		return
+	}
+
+	if err := s.db.MarkPostRead(r.Context(), database.MarkPostReadParams{UserID: user.ID, PostID: int32(postID)}); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *state) handlerAPIMarkUnread(w http.ResponseWriter, r *http.Request, user database.User) {
+	postID, err := strconv.ParseInt(r.PathValue("postID"), 10, 32)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid post id")
+		return
+	}
+
+	if err := s.db.MarkPostUnread(r.Context(), database.MarkPostUnreadParams{UserID: user.ID, PostID: int32(postID)}); err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
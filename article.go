@@ -0,0 +1,292 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// maxArticleSize stops a misbehaving page from filling up memory.
+	maxArticleSize = 5 << 20
+	articleTimeout = 20 * time.Second
+	// fullContentAttempts is how often agg tries a page before giving up on it.
+	fullContentAttempts = 5
+)
+
+var (
+	positiveHint = regexp.MustCompile(`(?i)article|body|content|entry|hentry|main|page|post|text|blog|story`)
+	negativeHint = regexp.MustCompile(`(?i)comment|meta|footer|footnote|sidebar|share|social|related|nav|menu|promo|widget|banner|masthead|sponsor|advert|popup|cookie|subscribe|newsletter`)
+)
+
+// boilerplateTags never hold the article itself.
+var boilerplateTags = map[string]bool{
+	"nav": true, "header": true, "footer": true, "aside": true, "form": true,
+	"button": true, "input": true, "select": true,
+}
+
+// htmlNode is an element or, when tag is empty, a run of escaped text.
+type htmlNode struct {
+	tag      string
+	text     string
+	attrs    []htmlAttr
+	children []*htmlNode
+	parent   *htmlNode
+}
+
+// parseHTMLTree builds a tree from tokenizeHTML, closing unclosed elements
+// when an ancestor ends and ignoring end tags that match nothing.
+func parseHTMLTree(s string) *htmlNode {
+	root := &htmlNode{tag: "#root"}
+	current := root
+	for _, token := range tokenizeHTML(s) {
+		switch {
+		case token.tag == "":
+			current.children = append(current.children, &htmlNode{text: token.text, parent: current})
+		case token.closing:
+			for n := current; n != root; n = n.parent {
+				if n.tag == token.tag {
+					current = n.parent
+					break
+				}
+			}
+		default:
+			if impliedEndTags[token.tag] && current.tag == token.tag {
+				current = current.parent
+			}
+			node := &htmlNode{tag: token.tag, attrs: token.attrs, parent: current}
+			current.children = append(current.children, node)
+			if !token.selfClosing && !isVoidElement(token.tag) {
+				current = node
+			}
+		}
+	}
+	return root
+}
+
+func isVoidElement(tag string) bool {
+	if voidTags[tag] {
+		return true
+	}
+	switch tag {
+	case "area", "base", "col", "embed", "input", "link", "meta", "param", "source", "track", "wbr":
+		return true
+	}
+	return false
+}
+
+func (n *htmlNode) attr(name string) string {
+	return htmlToken{attrs: n.attrs}.attr(name)
+}
+
+// textLength counts the characters of visible text under n, and how many of
+// them are inside links.
+func (n *htmlNode) textLength() (int, int) {
+	if n.tag == "" {
+		return len(strings.TrimSpace(html.UnescapeString(n.text))), 0
+	}
+	if skippedTags[n.tag] {
+		return 0, 0
+	}
+
+	var total, linked int
+	for _, child := range n.children {
+		t, l := child.textLength()
+		total += t
+		linked += l
+	}
+	if n.tag == "a" {
+		linked = total
+	}
+	return total, linked
+}
+
+func (n *htmlNode) innerText() string {
+	var b strings.Builder
+	var walk func(*htmlNode)
+	walk = func(n *htmlNode) {
+		if n.tag == "" {
+			b.WriteString(html.UnescapeString(n.text))
+			return
+		}
+		for _, child := range n.children {
+			walk(child)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+// render writes n back out as HTML, resolving links and images against base.
+func (n *htmlNode) render(b *strings.Builder, base *url.URL) {
+	if n.tag == "" {
+		b.WriteString(n.text)
+		return
+	}
+	if n.tag != "#root" {
+		b.WriteString("<" + n.tag)
+		for _, a := range n.attrs {
+			value := a.value
+			if (a.name == "href" || a.name == "src") && base != nil {
+				if ref, err := url.Parse(strings.TrimSpace(value)); err == nil {
+					value = base.ResolveReference(ref).String()
+				}
+			}
+			b.WriteString(" " + a.name + `="` + html.EscapeString(value) + `"`)
+		}
+		b.WriteString(">")
+	}
+	for _, child := range n.children {
+		child.render(b, base)
+	}
+	if n.tag != "#root" && !isVoidElement(n.tag) {
+		b.WriteString("</" + n.tag + ">")
+	}
+}
+
+// extractArticle finds the main body of a web page in the spirit of
+// Readability: paragraphs score their parent and grandparent by length and
+// commas, class and id names nudge the score, and link-heavy blocks are
+// penalised. The winner is returned as sanitized HTML.
+func extractArticle(page string, base *url.URL) (string, error) {
+	root := parseHTMLTree(page)
+	removeBoilerplate(root)
+
+	scores := map[*htmlNode]float64{}
+	var walk func(*htmlNode)
+	walk = func(n *htmlNode) {
+		for _, child := range n.children {
+			walk(child)
+		}
+		if n.tag != "p" && n.tag != "pre" && n.tag != "td" && n.tag != "blockquote" {
+			return
+		}
+
+		text := strings.TrimSpace(n.innerText())
+		if len(text) < 25 || n.parent == nil {
+			return
+		}
+		score := 1 + float64(strings.Count(text, ",")) + min(float64(len(text))/100, 3)
+
+		parent := n.parent
+		if _, ok := scores[parent]; !ok {
+			scores[parent] = classWeight(parent)
+		}
+		scores[parent] += score
+
+		if grandparent := parent.parent; grandparent != nil {
+			if _, ok := scores[grandparent]; !ok {
+				scores[grandparent] = classWeight(grandparent)
+			}
+			scores[grandparent] += score / 2
+		}
+	}
+	walk(root)
+
+	var best *htmlNode
+	bestScore := 0.0
+	for n, score := range scores {
+		total, linked := n.textLength()
+		if total == 0 {
+			continue
+		}
+		score *= 1 - float64(linked)/float64(total)
+		if score > bestScore {
+			best, bestScore = n, score
+		}
+	}
+	if best == nil {
+		return "", fmt.Errorf("no article found")
+	}
+
+	var b strings.Builder
+	best.render(&b, base)
+	return sanitizeHTML(b.String()), nil
+}
+
+// removeBoilerplate drops navigation, forms and blocks whose class or id
+// marks them as comments, sidebars and the like.
+func removeBoilerplate(n *htmlNode) {
+	kept := n.children[:0]
+	for _, child := range n.children {
+		if child.tag != "" {
+			if boilerplateTags[child.tag] || skippedTags[child.tag] {
+				continue
+			}
+			hints := child.attr("class") + " " + child.attr("id")
+			if child.tag != "body" && child.tag != "article" && negativeHint.MatchString(hints) && !positiveHint.MatchString(hints) {
+				continue
+			}
+		}
+		removeBoilerplate(child)
+		kept = append(kept, child)
+	}
+	n.children = kept
+}
+
+func classWeight(n *htmlNode) float64 {
+	weight := 0.0
+	switch n.tag {
+	case "article":
+		weight += 10
+	case "div", "section", "main":
+		weight += 5
+	case "td", "blockquote", "pre":
+		weight += 3
+	case "body":
+		weight -= 5
+	}
+
+	for _, hint := range []string{n.attr("class"), n.attr("id")} {
+		if hint == "" {
+			continue
+		}
+		if negativeHint.MatchString(hint) {
+			weight -= 25
+		}
+		if positiveHint.MatchString(hint) {
+			weight += 25
+		}
+	}
+	return weight
+}
+
+// fetchArticle downloads a post's page and extracts its main content.
+func fetchArticle(ctx context.Context, pageURL string) (string, error) {
+	base, err := url.Parse(pageURL)
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") {
+		return "", fmt.Errorf("not a web page: %v", pageURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", "gator")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	res, err := feedClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return "", fmt.Errorf("unexpected status fetching %v: %v", pageURL, res.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxArticleSize))
+	if err != nil {
+		return "", err
+	}
+	if !isHTML(data, res.Header.Get("Content-Type")) {
+		return "", fmt.Errorf("%v is not an html page", pageURL)
+	}
+
+	return extractArticle(string(data), res.Request.URL)
+}
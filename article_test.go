@@ -0,0 +1,82 @@
+package main
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestExtractArticle(t *testing.T) {
+	base, err := url.Parse("https://example.com/blog/post")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		page string
+		want string
+	}{
+		{
+			"boilerplate around the article",
+			`<html><body>` +
+				`<nav><a href="/">Home</a> <a href="/about">About the site, the author, everything</a></nav>` +
+				`<div id="sidebar"><p>Subscribe to the newsletter for more great posts, tips and tricks.</p></div>` +
+				`<article class="post"><h1>Title</h1>` +
+				`<p>The first paragraph of the story, with some commas, and plenty of text.</p>` +
+				`<p>A second paragraph that keeps going, because articles are long, usually.</p></article>` +
+				`<div class="comments"><p>Great post, thanks for writing it, I learned a lot today!</p></div>` +
+				`<footer><p>Copyright 2024 by somebody, all rights reserved, forever.</p></footer>` +
+				`</body></html>`,
+			`<h1>Title</h1>` +
+				`<p>The first paragraph of the story, with some commas, and plenty of text.</p>` +
+				`<p>A second paragraph that keeps going, because articles are long, usually.</p>`,
+		},
+		{
+			"relative links and images",
+			`<div class="content">` +
+				`<p>Read the <a href="../about">about page</a> for the full background, it explains everything.</p>` +
+				`<p><img src="/img/a.png" alt="A"> An image that sits next to a long caption, with commas.</p>` +
+				`</div>`,
+			`<div>` +
+				`<p>Read the <a href="https://example.com/about" rel="nofollow noopener noreferrer">about page</a> for the full background, it explains everything.</p>` +
+				`<p><img src="https://example.com/img/a.png" alt="A"> An image that sits next to a long caption, with commas.</p>` +
+				`</div>`,
+		},
+		{
+			"unclosed paragraphs and list items",
+			`<div class="entry">` +
+				`<p>First paragraph without a closing tag, but long enough to count` +
+				`<p>Second paragraph also without one, and also long enough to count` +
+				`<ul><li>one<li>two</ul>` +
+				`</div>`,
+			`<div>` +
+				`<p>First paragraph without a closing tag, but long enough to count</p>` +
+				`<p>Second paragraph also without one, and also long enough to count` +
+				`<ul><li>one</li><li>two</li></ul></p>` +
+				`</div>`,
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := extractArticle(tt.page, base)
+		if err != nil {
+			t.Errorf("%v: extractArticle returned error: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%v: extractArticle =\n%q\nwant\n%q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExtractArticleNotFound(t *testing.T) {
+	for _, page := range []string{
+		"",
+		`<html><body><nav><a href="/">Home</a></nav><p>Short.</p></body></html>`,
+		`<div class="comments"><p>Great post, thanks for writing it, I learned a lot today!</p></div>`,
+	} {
+		if got, err := extractArticle(page, nil); err == nil {
+			t.Errorf("extractArticle(%q) = %q, want error", page, got)
+		}
+	}
+}
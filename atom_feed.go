@@ -0,0 +1,106 @@
+package main
+
+import (
+	"strings"
+)
+
+const atomNamespace = "http://www.w3.org/2005/Atom"
+
+type AtomFeed struct {
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle"`
+	Updated  string      `xml:"updated"`
+	Link     []AtomLink  `xml:"link"`
+	Entry    []AtomEntry `xml:"entry"`
+}
+
+type AtomEntry struct {
+	ID        string         `xml:"id"`
+	Title     AtomText       `xml:"title"`
+	Link      []AtomLink     `xml:"link"`
+	Summary   AtomText       `xml:"summary"`
+	Content   AtomText       `xml:"content"`
+	Published string         `xml:"published"`
+	Updated   string         `xml:"updated"`
+	Author    []AtomPerson   `xml:"author"`
+	Category  []AtomCategory `xml:"category"`
+}
+
+type AtomPerson struct {
+	Name string `xml:"name"`
+}
+
+type AtomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type AtomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr,omitempty"`
+	Type   string `xml:"type,attr,omitempty"`
+	Length string `xml:"length,attr,omitempty"`
+}
+
+type AtomText struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+func (t AtomText) String() string {
+	if t.Type == "xhtml" {
+		return strings.TrimSpace(t.Inner)
+	}
+	return strings.TrimSpace(t.Text)
+}
+
+func alternateLink(links []AtomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return link.Href
+		}
+	}
+	if len(links) > 0 {
+		return links[0].Href
+	}
+	return ""
+}
+
+func (a *AtomFeed) toRSS() *RSSFeed {
+	var feed RSSFeed
+	feed.Channel.Title = a.Title
+	feed.Channel.Link = alternateLink(a.Link)
+	feed.Channel.Description = a.Subtitle
+	feed.Channel.LastBuildDate = a.Updated
+
+	for _, entry := range a.Entry {
+		item := RSSItem{
+			Title:       entry.Title.String(),
+			Link:        alternateLink(entry.Link),
+			Description: entry.Summary.String(),
+			PubDate:     entry.Published,
+			GUID:        RSSGUID{Value: entry.ID, IsPermaLink: "false"},
+			Content:     entry.Content.String(),
+		}
+		if item.Description == "" {
+			item.Description = item.Content
+		}
+		if len(entry.Author) > 0 {
+			item.Author = entry.Author[0].Name
+		}
+		for _, category := range entry.Category {
+			item.Categories = append(item.Categories, category.Term)
+		}
+		for _, link := range entry.Link {
+			if link.Rel == "enclosure" {
+				item.Enclosures = append(item.Enclosures, RSSEnclosure{URL: link.Href, Type: link.Type, Length: link.Length})
+			}
+		}
+		if item.PubDate == "" {
+			item.PubDate = entry.Updated
+		}
+		feed.Channel.Item = append(feed.Channel.Item, item)
+	}
+
+	return &feed
+}
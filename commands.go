@@ -1,14 +1,20 @@
 package main
 
-import "fmt"
+import (
+	"flag"
+	"fmt"
+	"io"
+)
 
 type command struct {
-	Name string
-	Args []string
+	Name   string
+	Args   []string
+	Output string
 }
 
 type commands struct {
 	commandMap map[string]func(*state, command) error
+	listing    map[string]bool
 }
 
 func (c *commands) run(s *state, cmd command) error {
@@ -20,6 +26,13 @@ func (c *commands) run(s *state, cmd command) error {
 	if !exists {
 		return fmt.Errorf("command does not exist")
 	} else {
+		if c.listing[cmd.Name] {
+			args, output, err := extractOutputFlag(cmd.Args)
+			if err != nil {
+				return err
+			}
+			cmd.Args, cmd.Output = args, output
+		}
 		if err := f(s, cmd); err != nil {
 			return err
 		}
@@ -31,3 +44,28 @@ func (c *commands) run(s *state, cmd command) error {
 func (c *commands) register(name string, f func(*state, command) error) {
 	c.commandMap[name] = f
 }
+
+// registerListing registers a command that takes --output. Other commands
+// don't, so the flag is reported as unknown there instead of being ignored.
+func (c *commands) registerListing(name string, f func(*state, command) error) {
+	c.register(name, f)
+	c.listing[name] = true
+}
+
+// parseFlags lets flags appear before, after or between positional arguments
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	fs.SetOutput(io.Discard)
+
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
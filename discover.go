@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var commonFeedPaths = []string{"/feed", "/rss.xml", "/atom.xml", "/feed.xml", "/index.xml", "/rss", "/feed.json"}
+
+// feedLinkTypes leaves out plain application/json, which WordPress also uses
+// to advertise its REST API.
+var feedLinkTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/feed+json": true,
+}
+
+// discoverTimeout bounds the requests made while resolving a page to a feed.
+const discoverTimeout = 30 * time.Second
+
+var (
+	linkTagRe = regexp.MustCompile(`(?is)<link\b[^>]*>`)
+	attrRe    = regexp.MustCompile(`(?is)([a-z:-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+)
+
+// discoverFeedURLs returns pageURL itself when it already serves a feed. For HTML
+// pages it returns the feeds advertised in <link rel="alternate"> tags, falling
+// back to the first common feed path that parses.
+func discoverFeedURLs(ctx context.Context, pageURL string) ([]string, error) {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "gator")
+
+	res, err := feedClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(res.Body, 5<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	if !isHTML(data, res.Header.Get("Content-Type")) {
+		return []string{pageURL}, nil
+	}
+
+	// links are resolved against the final URL in case of redirects
+	if res.Request != nil && res.Request.URL != nil {
+		base = res.Request.URL
+	}
+
+	if found := feedLinks(base, string(data)); len(found) > 0 {
+		return found, nil
+	}
+
+	for _, path := range commonFeedPaths {
+		candidate := base.ResolveReference(&url.URL{Path: path}).String()
+		if _, err := fetchFeed(ctx, candidate); err == nil {
+			return []string{candidate}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no feed found at %v", pageURL)
+}
+
+func isHTML(data []byte, contentType string) bool {
+	if strings.Contains(strings.ToLower(contentType), "html") {
+		return true
+	}
+	start := strings.ToLower(strings.TrimSpace(string(data[:min(len(data), 512)])))
+	return strings.HasPrefix(start, "<!doctype html") || strings.HasPrefix(start, "<html")
+}
+
+func feedLinks(base *url.URL, page string) []string {
+	var found []string
+	seen := make(map[string]bool)
+
+	for _, tag := range linkTagRe.FindAllString(page, -1) {
+		attrs := make(map[string]string)
+		for _, match := range attrRe.FindAllStringSubmatch(tag, -1) {
+			attrs[strings.ToLower(match[1])] = match[2] + match[3] + match[4]
+		}
+
+		isAlternate := false
+		for _, rel := range strings.Fields(strings.ToLower(attrs["rel"])) {
+			if rel == "alternate" {
+				isAlternate = true
+			}
+		}
+		linkType := strings.ToLower(strings.TrimSpace(attrs["type"]))
+		if !isAlternate || !feedLinkTypes[linkType] || attrs["href"] == "" {
+			continue
+		}
+
+		href, err := url.Parse(strings.TrimSpace(attrs["href"]))
+		if err != nil {
+			continue
+		}
+		feedURL := base.ResolveReference(href).String()
+		if !seen[feedURL] {
+			seen[feedURL] = true
+			found = append(found, feedURL)
+		}
+	}
+
+	return found
+}
+
+// resolveFeedURL picks the first discovered feed for rawURL and tells the user
+// about any alternatives. If the page can't be reached the URL is kept as is.
+func resolveFeedURL(ctx context.Context, rawURL string) string {
+	urls, err := discoverFeedURLs(ctx, rawURL)
+	if err != nil {
+		fmt.Printf("Couldn't discover a feed at %v: %v\n", rawURL, err)
+		return rawURL
+	}
+
+	if urls[0] != rawURL {
+		fmt.Printf("Discovered feed %v from %v\n", urls[0], rawURL)
+	}
+	if len(urls) > 1 {
+		fmt.Println("Other feeds found on the page, pass one of these URLs instead to use it:")
+		for _, other := range urls[1:] {
+			fmt.Printf("  %v\n", other)
+		}
+	}
+
+	return urls[0]
+}
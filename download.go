@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/scottyloveless/gator/internal/database"
+)
+
+const (
+	downloadComplete    = "complete"
+	downloadFailed      = "failed"
+	downloadDownloading = "downloading"
+)
+
+// downloadEnclosure fetches an enclosure into dir/<feed>/<post id>-<file>. Partial
+// data is kept in a .part file and resumed with a Range request next time.
+func downloadEnclosure(ctx context.Context, db *database.Queries, enclosure database.GetEnclosuresForUserRow, dir string) error {
+	target := filepath.Join(dir, safeFileName(enclosure.FeedName), fmt.Sprintf("%d-%s", enclosure.PostID, enclosureFileName(enclosure.Url)))
+	partial := target + ".part"
+
+	if enclosure.Status.String == downloadComplete {
+		if _, err := os.Stat(enclosure.Path.String); err == nil {
+			return nil
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	written, total, err := fetchToFile(ctx, db, enclosure.ID, enclosure.Url, target, partial)
+	if err != nil {
+		if dbErr := db.UpsertDownload(context.WithoutCancel(ctx), database.UpsertDownloadParams{
+			EnclosureID:     enclosure.ID,
+			Path:            target,
+			Status:          downloadFailed,
+			BytesDownloaded: written,
+			TotalBytes:      total,
+			LastError:       sql.NullString{String: err.Error(), Valid: true},
+		}); dbErr != nil {
+			return errors.Join(err, fmt.Errorf("couldn't record the failed download: %w", dbErr))
+		}
+		return err
+	}
+
+	if err := os.Rename(partial, target); err != nil {
+		return err
+	}
+
+	return db.UpsertDownload(ctx, database.UpsertDownloadParams{
+		EnclosureID:     enclosure.ID,
+		Path:            target,
+		Status:          downloadComplete,
+		BytesDownloaded: written,
+		TotalBytes:      sql.NullInt64{Int64: written, Valid: true},
+	})
+}
+
+func fetchToFile(ctx context.Context, db *database.Queries, enclosureID int32, mediaURL, target, partial string) (int64, sql.NullInt64, error) {
+	var offset int64
+	if info, err := os.Stat(partial); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, mediaURL, nil)
+	if err != nil {
+		return offset, sql.NullInt64{}, err
+	}
+	req.Header.Set("User-Agent", "gator")
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	res, err := feedClient.Do(req)
+	if err != nil {
+		return offset, sql.NullInt64{}, err
+	}
+	defer res.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch res.StatusCode {
+	case http.StatusPartialContent:
+		if start, ok := contentRangeStart(res.Header.Get("Content-Range")); !ok || start != offset {
+			if offset == 0 {
+				return 0, sql.NullInt64{}, fmt.Errorf("unexpected Content-Range: %q", res.Header.Get("Content-Range"))
+			}
+			// the server sent a different range than we asked for, start over
+			res.Body.Close()
+			if err := os.Remove(partial); err != nil {
+				return offset, sql.NullInt64{}, err
+			}
+			return fetchToFile(ctx, db, enclosureID, mediaURL, target, partial)
+		}
+		flags |= os.O_APPEND
+	case http.StatusOK:
+		// the server ignored the range, start over
+		offset = 0
+		flags |= os.O_TRUNC
+	case http.StatusRequestedRangeNotSatisfiable:
+		// the partial file already holds everything
+		return offset, sql.NullInt64{Int64: offset, Valid: true}, nil
+	default:
+		return offset, sql.NullInt64{}, fmt.Errorf("unexpected status: %s", res.Status)
+	}
+
+	total := sql.NullInt64{}
+	if res.ContentLength >= 0 {
+		total = sql.NullInt64{Int64: offset + res.ContentLength, Valid: true}
+	}
+
+	if err := db.UpsertDownload(ctx, database.UpsertDownloadParams{
+		EnclosureID:     enclosureID,
+		Path:            target,
+		Status:          downloadDownloading,
+		BytesDownloaded: offset,
+		TotalBytes:      total,
+	}); err != nil {
+		return offset, total, err
+	}
+
+	file, err := os.OpenFile(partial, flags, 0644)
+	if err != nil {
+		return offset, total, err
+	}
+	defer file.Close()
+
+	n, err := io.Copy(file, res.Body)
+	written := offset + n
+	if err != nil {
+		return written, total, err
+	}
+	if total.Valid && written < total.Int64 {
+		return written, total, errors.New("download ended early")
+	}
+
+	return written, total, file.Close()
+}
+
+// contentRangeStart returns the first byte of a "bytes start-end/total" header.
+func contentRangeStart(header string) (int64, bool) {
+	spec, ok := strings.CutPrefix(header, "bytes ")
+	if !ok {
+		return 0, false
+	}
+	start, _, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(start), 10, 64)
+	return n, err == nil
+}
+
+func enclosureFileName(mediaURL string) string {
+	name := "media"
+	if u, err := url.Parse(mediaURL); err == nil {
+		if base := path.Base(u.Path); base != "." && base != "/" {
+			name = base
+		}
+	}
+	return safeFileName(name)
+}
+
+func safeFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		if r < 32 {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "" || name == "." || name == ".." {
+		return "untitled"
+	}
+	return name
+}
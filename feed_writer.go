@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"time"
+
+	"github.com/scottyloveless/gator/internal/database"
+)
+
+type rssOutput struct {
+	XMLName xml.Name         `xml:"rss"`
+	Version string           `xml:"version,attr"`
+	Channel rssOutputChannel `xml:"channel"`
+}
+
+type rssOutputChannel struct {
+	Title         string          `xml:"title"`
+	Link          string          `xml:"link,omitempty"`
+	Description   string          `xml:"description"`
+	LastBuildDate string          `xml:"lastBuildDate"`
+	Items         []rssOutputItem `xml:"item"`
+}
+
+type rssOutputItem struct {
+	Title       string          `xml:"title"`
+	Link        string          `xml:"link"`
+	Description string          `xml:"description"`
+	PubDate     string          `xml:"pubDate"`
+	GUID        rssOutputGUID   `xml:"guid"`
+	Source      rssOutputSource `xml:"source"`
+}
+
+type rssOutputGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssOutputSource struct {
+	URL  string `xml:"url,attr"`
+	Name string `xml:",chardata"`
+}
+
+type atomOutput struct {
+	XMLName xml.Name          `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string            `xml:"title"`
+	ID      string            `xml:"id"`
+	Updated string            `xml:"updated"`
+	Link    []AtomLink        `xml:"link"`
+	Entries []atomOutputEntry `xml:"entry"`
+}
+
+type atomOutputEntry struct {
+	Title     string           `xml:"title"`
+	ID        string           `xml:"id"`
+	Link      AtomLink         `xml:"link"`
+	Published string           `xml:"published"`
+	Updated   string           `xml:"updated"`
+	Summary   atomOutputText   `xml:"summary"`
+	Source    atomOutputSource `xml:"source"`
+}
+
+type atomOutputText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type atomOutputSource struct {
+	Title string   `xml:"title"`
+	ID    string   `xml:"id"`
+	Link  AtomLink `xml:"link"`
+}
+
+// renderTimeline writes a user's posts as an RSS 2.0 or Atom document, keeping
+// the originating feed on every item so readers can attribute it. selfURL may
+// be empty when the document isn't served from a known address, the channel
+// link is left out then.
+func renderTimeline(format string, user database.User, selfURL string, posts []database.GetPostsWithFeedForUserRow) ([]byte, error) {
+	title := timelineTitle(user)
+	updated := time.Now()
+	if len(posts) > 0 {
+		updated = posts[0].PublishedAt
+	}
+
+	var doc any
+	switch format {
+	case "rss":
+		channel := rssOutputChannel{
+			Title:         title,
+			Link:          selfURL,
+			Description:   title,
+			LastBuildDate: updated.Format(time.RFC1123Z),
+		}
+		for _, post := range posts {
+			channel.Items = append(channel.Items, rssOutputItem{
+				Title:       post.Title,
+				Link:        post.Url,
+				Description: safeDescription(post.SafeHtml, post.Description),
+				PubDate:     post.PublishedAt.Format(time.RFC1123Z),
+				GUID:        rssOutputGUID{IsPermaLink: "true", Value: post.Url},
+				Source:      rssOutputSource{URL: post.FeedUrl, Name: post.FeedName},
+			})
+		}
+		doc = rssOutput{Version: "2.0", Channel: channel}
+	case "atom":
+		feed := atomOutput{
+			Title:   title,
+			ID:      "urn:uuid:" + user.ID.String(),
+			Updated: updated.Format(time.RFC3339),
+		}
+		if selfURL != "" {
+			feed.Link = []AtomLink{{Href: selfURL, Rel: "self"}}
+		}
+		for _, post := range posts {
+			feed.Entries = append(feed.Entries, atomOutputEntry{
+				Title:     post.Title,
+				ID:        post.Url,
+				Link:      AtomLink{Href: post.Url, Rel: "alternate"},
+				Published: post.PublishedAt.Format(time.RFC3339),
+				Updated:   post.UpdatedAt.Format(time.RFC3339),
+				Summary:   atomOutputText{Type: "html", Value: safeDescription(post.SafeHtml, post.Description)},
+				Source: atomOutputSource{
+					Title: post.FeedName,
+					ID:    post.FeedUrl,
+					Link:  AtomLink{Href: post.FeedUrl, Rel: "self"},
+				},
+			})
+		}
+		doc = feed
+	default:
+		return nil, fmt.Errorf("unknown feed format %q, use rss or atom", format)
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	data = append([]byte(xml.Header), data...)
+	return append(data, '\n'), nil
+}
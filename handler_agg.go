@@ -2,83 +2,302 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/scottyloveless/gator/internal/database"
 )
 
 func handlerAgg(s *state, cmd command) error {
-	if len(cmd.Args) < 1 || len(cmd.Args) > 2 {
-		return fmt.Errorf("usage: %v <time_between_reqs>", cmd.Name)
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	concurrency := fs.Int("concurrency", 1, "number of feeds to fetch in parallel")
+	timeout := fs.Duration("timeout", 30*time.Second, "time limit for fetching a single feed")
+	prune := fs.Bool("prune", false, "delete posts past their retention after each round")
+
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil || len(args) != 1 {
+		return fmt.Errorf("usage: %v <time_between_reqs> [--concurrency n] [--timeout 30s] [--prune]", cmd.Name)
 	}
 
-	timeBetweenRequests, err := time.ParseDuration(cmd.Args[0])
+	timeBetweenRequests, err := time.ParseDuration(args[0])
 	if err != nil {
 		return fmt.Errorf("invalid duration: %w", err)
 	}
+	if *concurrency < 1 {
+		return fmt.Errorf("concurrency must be at least 1")
+	}
 
-	log.Printf("Collecting feeds every %s...", timeBetweenRequests)
+	log.Printf("Collecting feeds every %s with %d workers...", timeBetweenRequests, *concurrency)
+
+	go fetchFullContent(s, timeBetweenRequests)
 
 	ticker := time.NewTicker(timeBetweenRequests)
 
 	for ; ; <-ticker.C {
-		scrapeFeeds(s)
+		scrapeFeeds(s, *concurrency, timeBetweenRequests, *timeout)
+		if *prune {
+			logPrune(s)
+		}
 	}
 }
 
-func scrapeFeeds(s *state) {
-	feed, err := s.db.GetNextFeedToFetch(context.Background())
-	if err != nil {
-		log.Println("Couldn't get next feeds to fetch", err)
-		return
+// scrapeFeeds runs concurrency workers that each claim one stale feed at a
+// time until none are left, so a slow feed only holds up its own worker.
+// Claiming marks the feed fetched under SKIP LOCKED, so several agg processes
+// can share the same database without fetching the same feed twice.
+func scrapeFeeds(s *state, concurrency int, staleAfter, timeout time.Duration) {
+	var wg sync.WaitGroup
+	for range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				feeds, err := s.db.ClaimFeedsToFetch(context.Background(), database.ClaimFeedsToFetchParams{
+					StaleSeconds: staleAfter.Seconds(),
+					BatchSize:    1,
+				})
+				if err != nil {
+					log.Println("Couldn't get next feed to fetch", err)
+					return
+				}
+				if len(feeds) == 0 {
+					return
+				}
+
+				feed := feeds[0]
+				log.Printf("Found a feed to fetch! Name: %v\n", feed.Name)
+				ctx, cancel := context.WithTimeout(context.Background(), timeout)
+				scrapeFeed(ctx, s.db, feed)
+				cancel()
+			}
+		}()
 	}
-	log.Printf("Found a feed to fetch! Name: %v\n", feed.Name)
-	scrapeFeed(s.db, feed)
+	wg.Wait()
 }
 
-func scrapeFeed(db *database.Queries, feed database.Feed) {
-	_, err := db.MarkFeedFetched(context.Background(), feed.ID)
-	if err != nil {
-		log.Printf("Couldn't mark feed %s fetched: %v", feed.Name, err)
+func scrapeFeed(ctx context.Context, db *database.Queries, feed database.Feed) {
+	// Posts and bookkeeping are still written when the fetch ran out of time.
+	writeCtx := context.WithoutCancel(ctx)
+
+	feedData, validators, err := fetchFeedConditional(ctx, feed.Url, feedValidators{
+		ETag:         feed.Etag.String,
+		LastModified: feed.LastModified.String,
+	})
+	if err != nil && !errors.Is(err, errFeedNotModified) {
+		log.Printf("Couldn't collect feed %s: %v", feed.Name, err)
+		if err := db.MarkFeedFailed(writeCtx, database.MarkFeedFailedParams{
+			ID:        feed.ID,
+			LastError: sql.NullString{String: err.Error(), Valid: true},
+		}); err != nil {
+			log.Printf("Couldn't record failure for feed %s: %v", feed.Name, err)
+		}
 		return
 	}
 
-	feedData, err := fetchFeed(context.Background(), feed.Url)
-	if err != nil {
-		log.Printf("Couldn't collect feed %s: %v", feed.Name, err)
+	if errors.Is(err, errFeedNotModified) {
+		log.Printf("Feed %s not modified since last fetch", feed.Name)
+		markFeedFetched(writeCtx, db, feed, validators)
 		return
 	}
 
+	if feedData.Channel.Link != "" && feedData.Channel.Link != feed.SiteUrl.String {
+		if err := db.SetFeedSiteURL(writeCtx, database.SetFeedSiteURLParams{
+			ID:      feed.ID,
+			SiteUrl: sql.NullString{String: feedData.Channel.Link, Valid: true},
+		}); err != nil {
+			log.Printf("Couldn't update site url for feed %s: %v", feed.Name, err)
+		}
+	}
+
+	failed := 0
+	fallbackTime := feedFallbackTime(feedData)
 	for _, item := range feedData.Channel.Item {
 		pubTime, err := parseTime(item.PubDate)
 		if err != nil {
-			log.Printf("%v", err)
+			if item.PubDate != "" {
+				log.Printf("%v", err)
+			}
+			pubTime = fallbackTime
+		}
+
+		if err := savePost(writeCtx, db, feed, item, pubTime); err != nil {
+			log.Printf("Couldn't save post %s: %v", item.Link, err)
+			failed++
 		}
+	}
+
+	// Without validators the next fetch gets the whole feed again, so items
+	// that couldn't be saved aren't hidden behind a 304.
+	if failed > 0 {
+		validators = feedValidators{}
+	}
+	markFeedFetched(writeCtx, db, feed, validators)
+	log.Printf("Feed %s collected, %v posts found", feed.Name, len(feedData.Channel.Item))
+}
 
-		db.CreatePost(context.Background(), database.CreatePostParams{
+func markFeedFetched(ctx context.Context, db *database.Queries, feed database.Feed, validators feedValidators) {
+	if _, err := db.MarkFeedFetched(ctx, database.MarkFeedFetchedParams{
+		ID:           feed.ID,
+		Etag:         sql.NullString{String: validators.ETag, Valid: validators.ETag != ""},
+		LastModified: sql.NullString{String: validators.LastModified, Valid: validators.LastModified != ""},
+	}); err != nil {
+		log.Printf("Couldn't mark feed %s fetched: %v", feed.Name, err)
+	}
+}
+
+// savePost stores a new item with its categories and enclosures. Items are
+// deduplicated on their guid within the feed, or on their url when they have none,
+// and items removed by prune are not collected again.
+func savePost(ctx context.Context, db *database.Queries, feed database.Feed, item RSSItem, pubTime time.Time) error {
+	guid := strings.TrimSpace(item.GUID.Value)
+	link := strings.TrimSpace(item.Link)
+	if link == "" && guid != "" && item.GUID.permaLink() {
+		link = guid
+	}
+
+	safeHTML := sanitizeHTML(item.Description)
+	summary := excerpt(safeHTML, storedExcerptLength)
+
+	if guid != "" {
+		// Posts stored before guids were tracked only have their url, give them
+		// the guid so they aren't inserted a second time.
+		if _, err := db.AdoptPostGUID(ctx, database.AdoptPostGUIDParams{
+			FeedID:          feed.ID,
+			Url:             link,
+			Guid:            sql.NullString{String: guid, Valid: true},
+			GuidIsPermalink: sql.NullBool{Bool: item.GUID.permaLink(), Valid: true},
+		}); err != nil {
+			return err
+		}
+	}
+
+	postID, err := db.CreatePost(ctx, database.CreatePostParams{
+		Title:           item.Title,
+		Url:             link,
+		Description:     item.Description,
+		PublishedAt:     pubTime,
+		FeedID:          feed.ID,
+		Guid:            sql.NullString{String: guid, Valid: guid != ""},
+		GuidIsPermalink: sql.NullBool{Bool: item.GUID.permaLink(), Valid: guid != ""},
+		Author:          sql.NullString{String: item.author(), Valid: item.author() != ""},
+		Content:         sql.NullString{String: item.Content, Valid: item.Content != ""},
+		SafeHtml:        sql.NullString{String: safeHTML, Valid: true},
+		Excerpt:         sql.NullString{String: summary, Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// Already stored: keep the old version as a revision if the feed edited it.
+		updated, err := db.UpdatePostIfChanged(ctx, database.UpdatePostIfChangedParams{
+			Guid:        sql.NullString{String: guid, Valid: guid != ""},
+			FeedID:      feed.ID,
+			Url:         link,
 			Title:       item.Title,
-			Url:         item.Link,
 			Description: item.Description,
-			PublishedAt: pubTime,
-			FeedID:      feed.ID,
+			Content:     sql.NullString{String: item.Content, Valid: item.Content != ""},
+			SafeHtml:    sql.NullString{String: safeHTML, Valid: true},
+			Excerpt:     sql.NullString{String: summary, Valid: true},
 		})
+		if err != nil {
+			return err
+		}
+		if updated > 0 {
+			log.Printf("Post updated: %s", item.Title)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, category := range item.Categories {
+		if category = strings.TrimSpace(category); category == "" {
+			continue
+		}
+		if err := db.CreatePostCategory(ctx, database.CreatePostCategoryParams{PostID: postID, Name: category}); err != nil {
+			return err
+		}
 	}
-	log.Printf("Feed %s collected, %v posts found", feed.Name, len(feedData.Channel.Item))
-}
 
-var timeLayouts = []string{
-	"Mon, 02 Jan 2006 15:04:05 -0700",
-	"Mon, 02 Jan 2006 15:04:05 MST",
-	"Mon, 2 Jan 2006 15:04:05 -0700",
+	for _, enclosure := range item.Enclosures {
+		if enclosure.URL == "" {
+			continue
+		}
+		length, parseErr := strconv.ParseInt(strings.TrimSpace(enclosure.Length), 10, 64)
+		if err := db.CreatePostEnclosure(ctx, database.CreatePostEnclosureParams{
+			PostID: postID,
+			Url:    enclosure.URL,
+			Type:   sql.NullString{String: enclosure.Type, Valid: enclosure.Type != ""},
+			Length: sql.NullInt64{Int64: length, Valid: parseErr == nil && length > 0},
+		}); err != nil {
+			return err
+		}
+	}
+
+	if feed.FetchFullContent && link != "" {
+		if err := db.QueueFullContent(ctx, postID); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
-func parseTime(timestamp string) (time.Time, error) {
-	for _, tl := range timeLayouts {
-		if t, err := time.Parse(tl, timestamp); err == nil {
-			return t, nil
+// fetchFullContent works through the pages queued by savePost, one at a
+// time, so slow article pages never hold up fetching feeds. When the queue
+// is empty it waits for the next round of agg. A page that can't be fetched
+// is retried with a growing delay, up to fullContentAttempts times.
+func fetchFullContent(s *state, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	for ; ; <-ticker.C {
+		for {
+			jobs, err := s.db.ClaimFullContentJobs(context.Background(), database.ClaimFullContentJobsParams{
+				MaxAttempts: fullContentAttempts,
+				RowLimit:    1,
+			})
+			if err != nil {
+				log.Println("Couldn't get next article to fetch", err)
+				break
+			}
+			if len(jobs) == 0 {
+				break
+			}
+
+			job := jobs[0]
+			if err := saveFullContent(context.Background(), s.db, job.ID, job.Url); err != nil {
+				log.Printf("Couldn't get full content for %s: %v", job.Url, err)
+				if err := s.db.FailFullContentJob(context.Background(), database.FailFullContentJobParams{
+					PostID:    job.ID,
+					LastError: sql.NullString{String: err.Error(), Valid: true},
+				}); err != nil {
+					log.Printf("Couldn't record failure for %s: %v", job.Url, err)
+				}
+			}
 		}
 	}
-	return time.Time{}, fmt.Errorf("unrecognized time format: %s", timestamp)
+}
+
+// saveFullContent stores the readable body of a post's web page and takes
+// it off the queue.
+func saveFullContent(ctx context.Context, db *database.Queries, postID int32, link string) error {
+	ctx, cancel := context.WithTimeout(ctx, articleTimeout)
+	defer cancel()
+
+	article, err := fetchArticle(ctx, link)
+	if err != nil {
+		return err
+	}
+
+	if err := db.SetPostFullContent(ctx, database.SetPostFullContentParams{
+		ID:          postID,
+		FullContent: sql.NullString{String: article, Valid: true},
+	}); err != nil {
+		return err
+	}
+	return db.FinishFullContentJob(ctx, postID)
 }
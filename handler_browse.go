@@ -2,50 +2,163 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"flag"
 	"fmt"
 	"log"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/scottyloveless/gator/internal/database"
 )
 
-func browse(s *state, limit ...int) {
-	limitActual := 2
-	if len(limit) > 0 {
-		limitActual = limit[0]
-	}
-
-	posts, err := s.db.GetPostsForUser(context.Background(), database.GetPostsForUserParams{Name: s.cfg.CurrentUserName, Limit: int32(limitActual)})
+func browse(s *state, output string, params database.BrowsePostsForUserParams, summaryLength int, full bool) error {
+	posts, err := s.db.BrowsePostsForUser(context.Background(), params)
 	if err != nil {
-		log.Printf("%v", err)
-	}
-
-	if len(posts) == 0 {
-		log.Printf("no posts found")
+		return err
 	}
 
+	rows := make([]apiPost, 0, len(posts))
 	for _, post := range posts {
-		printPost(post)
+		row := toAPIPost(browsedPost(post))
+		row.Highlight = post.Highlight.String
+		rows = append(rows, row)
 	}
+
+	return render(output, rows, func() error {
+		if len(posts) == 0 {
+			log.Printf("no posts found")
+			return nil
+		}
+
+		for _, post := range posts {
+			printPost(browsedPost(post), summaryLength, post.Highlight.String)
+			if full {
+				printArticle(browsedPost(post))
+			}
+		}
+
+		if len(posts) == int(params.RowLimit) {
+			fmt.Printf("\nMore posts available, continue with --after %v\n", posts[len(posts)-1].ID)
+		}
+		return nil
+	})
 }
 
 func handlerBrowse(s *state, cmd command) error {
-	if len(cmd.Args) < 1 || len(cmd.Args) > 1 {
-		return fmt.Errorf("format is browse 10")
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	unread := fs.Bool("unread", false, "only show posts that haven't been read")
+	after := fs.Int("after", 0, "show posts that come after this post id")
+	page := fs.Int("page", 1, "page number, counting from the first post or --after")
+	feed := fs.String("feed", "", "only show posts from this feed url or name")
+	since := fs.String("since", "", "only show posts published after this date or duration, e.g. 24h or 2024-01-31")
+	before := fs.String("before", "", "only show posts published before this date or duration")
+	title := fs.String("title", "", "only show posts with this text in the title")
+	sort := fs.String("sort", "newest", "sort order: newest, oldest or title")
+	full := fs.Bool("full", false, "print the whole article after each post")
+	length := fs.Int("length", s.cfg.SummaryLength(), "characters of each summary to show, 0 hides them")
+	muted := fs.Bool("muted", false, "include posts hidden by mute rules")
+
+	usage := fmt.Errorf("usage: %v [limit] [--unread] [--after post_id] [--page n] [--feed url|name] [--since 24h] [--before 2024-01-31] [--title text] [--sort newest|oldest|title] [--length 280] [--full] [--muted]", cmd.Name)
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil || len(args) > 1 {
+		return usage
 	}
 
-	intify, err := strconv.Atoi(cmd.Args[0])
-	if err != nil {
-		return fmt.Errorf("enter valid integer after browse")
+	limit := 2
+	if len(args) == 1 {
+		limit, err = strconv.Atoi(args[0])
+		if err != nil || limit < 1 {
+			return fmt.Errorf("enter valid integer after browse")
+		}
+	}
+	if *page < 1 || *after < 0 || *length < 0 {
+		return usage
+	}
+	if *sort != "newest" && *sort != "oldest" && *sort != "title" {
+		return fmt.Errorf("unknown sort order %q, use newest, oldest or title", *sort)
+	}
+
+	params := database.BrowsePostsForUserParams{
+		Name:       s.cfg.CurrentUserName,
+		UnreadOnly: *unread,
+		Feed:       sql.NullString{String: *feed, Valid: *feed != ""},
+		Title:      sql.NullString{String: *title, Valid: *title != ""},
+		AfterID:    sql.NullInt32{Int32: int32(*after), Valid: *after > 0},
+		Sort:       *sort,
+		ShowMuted:  *muted,
+		RowLimit:   int32(limit),
+		RowOffset:  int32((*page - 1) * limit),
 	}
 
-	browse(s, intify)
+	if *after > 0 {
+		if _, err := s.db.GetPost(context.Background(), int32(*after)); err != nil {
+			return fmt.Errorf("no post found with id %v", *after)
+		}
+	}
+	if *since != "" {
+		sinceTime, err := parseTimeBound(*since, time.Now())
+		if err != nil {
+			return err
+		}
+		params.Since = sql.NullTime{Time: sinceTime, Valid: true}
+	}
+	if *before != "" {
+		beforeTime, err := parseTimeBound(*before, time.Now())
+		if err != nil {
+			return err
+		}
+		params.Before = sql.NullTime{Time: beforeTime, Valid: true}
+	}
 
-	return nil
+	return browse(s, cmd.Output, params, *length, *full)
 }
 
-func printPost(post database.Post) {
+// summaryWidth keeps wrapped summaries inside an 80 column terminal.
+const summaryWidth = 60
+
+func printPost(post database.Post, summaryLength int, highlight string) {
+	fmt.Printf(" * ID:             %v\n", post.ID)
 	fmt.Printf(" * Title:          %v\n", post.Title)
+	if highlight != "" {
+		fmt.Printf(" * Highlight:      matches %q\n", highlight)
+	}
+	if post.EditedAt.Valid {
+		fmt.Printf(" * Updated:        %v (see history %v)\n", post.EditedAt.Time.Format(time.DateTime), post.ID)
+	}
 	fmt.Printf(" * URL:            %v\n", post.Url)
-	fmt.Printf(" * Description:    %v\n", post.Description)
+	if summaryLength > 0 {
+		lines := wrapText(postExcerpt(post, summaryLength), summaryWidth)
+		fmt.Printf(" * Summary:        %v\n", strings.Join(lines, "\n                   "))
+	}
+}
+
+func printArticle(post database.Post) {
+	fmt.Println(" * Article:")
+	for _, line := range wrapText(htmlToText(articleHTML(post)), 76) {
+		fmt.Printf("   %v\n", line)
+	}
+	fmt.Println()
+}
+
+func browsedPost(row database.BrowsePostsForUserRow) database.Post {
+	return database.Post{
+		ID:              row.ID,
+		CreatedAt:       row.CreatedAt,
+		UpdatedAt:       row.UpdatedAt,
+		Title:           row.Title,
+		Url:             row.Url,
+		Description:     row.Description,
+		PublishedAt:     row.PublishedAt,
+		FeedID:          row.FeedID,
+		Guid:            row.Guid,
+		GuidIsPermalink: row.GuidIsPermalink,
+		Author:          row.Author,
+		Content:         row.Content,
+		EditedAt:        row.EditedAt,
+		SafeHtml:        row.SafeHtml,
+		Excerpt:         row.Excerpt,
+		FullContent:     row.FullContent,
+	}
 }
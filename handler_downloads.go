@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/scottyloveless/gator/internal/database"
+)
+
+type episode struct {
+	PostID    int32  `json:"post_id"`
+	PostTitle string `json:"post_title"`
+	Feed      string `json:"feed"`
+	URL       string `json:"url"`
+	Status    string `json:"status"`
+	File      string `json:"file,omitempty"`
+}
+
+func handlerPodcast(s *state, cmd command) error {
+	if len(cmd.Args) != 2 || (cmd.Args[1] != "on" && cmd.Args[1] != "off") {
+		return fmt.Errorf("usage: %v <feed_url> on|off", cmd.Name)
+	}
+
+	count, err := s.db.SetFeedPodcast(context.Background(), database.SetFeedPodcastParams{
+		Url:       cmd.Args[0],
+		IsPodcast: cmd.Args[1] == "on",
+	})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("no feed found at URL: %v", cmd.Args[0])
+	}
+
+	fmt.Printf("podcast downloads turned %v for %v\n", cmd.Args[1], cmd.Args[0])
+	return nil
+}
+
+func handlerDownloads(s *state, cmd command, user database.User) error {
+	enclosures, err := s.db.GetEnclosuresForUser(context.Background(), database.GetEnclosuresForUserParams{UserID: user.ID})
+	if err != nil {
+		return err
+	}
+
+	rows := make([]episode, 0, len(enclosures))
+	for _, enclosure := range enclosures {
+		rows = append(rows, episode{
+			PostID:    enclosure.PostID,
+			PostTitle: enclosure.PostTitle,
+			Feed:      enclosure.FeedName,
+			URL:       enclosure.Url,
+			Status:    downloadStatus(enclosure),
+			File:      enclosure.Path.String,
+		})
+	}
+
+	return render(cmd.Output, rows, func() error {
+		if len(enclosures) == 0 {
+			fmt.Println("no podcast episodes found, mark a feed with: gator podcast <feed_url> on")
+			return nil
+		}
+
+		for _, enclosure := range enclosures {
+			fmt.Printf(" * Post:     %v (%v)\n", enclosure.PostTitle, enclosure.PostID)
+			fmt.Printf(" * Feed:     %v\n", enclosure.FeedName)
+			fmt.Printf(" * Media:    %v\n", enclosure.Url)
+			fmt.Printf(" * Status:   %v\n", downloadStatus(enclosure))
+			if enclosure.Path.Valid {
+				fmt.Printf(" * File:     %v\n", enclosure.Path.String)
+			}
+			fmt.Println()
+		}
+		return nil
+	})
+}
+
+func handlerDownload(s *state, cmd command, user database.User) error {
+	if len(cmd.Args) > 1 {
+		return fmt.Errorf("usage: %v [post_id]", cmd.Name)
+	}
+
+	params := database.GetEnclosuresForUserParams{UserID: user.ID}
+	if len(cmd.Args) == 1 {
+		postID, err := parsePostID(cmd)
+		if err != nil {
+			return err
+		}
+		params.PostID = sql.NullInt32{Int32: postID, Valid: true}
+	}
+
+	enclosures, err := s.db.GetEnclosuresForUser(context.Background(), params)
+	if err != nil {
+		return err
+	}
+	if len(enclosures) == 0 {
+		return fmt.Errorf("nothing to download")
+	}
+
+	dir, err := s.cfg.DownloadPath()
+	if err != nil {
+		return err
+	}
+
+	var failed int
+	for _, enclosure := range enclosures {
+		if enclosure.Status.String == downloadComplete && len(cmd.Args) == 0 {
+			continue
+		}
+		fmt.Printf("Downloading %v...\n", enclosure.Url)
+		if err := downloadEnclosure(context.Background(), s.db, enclosure, dir); err != nil {
+			fmt.Printf("failed to download %v: %v\n", enclosure.Url, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d downloads failed, run download again to resume", failed)
+	}
+
+	fmt.Printf("downloads saved to %v\n", dir)
+	return nil
+}
+
+func downloadStatus(enclosure database.GetEnclosuresForUserRow) string {
+	if !enclosure.Status.Valid {
+		return "not downloaded"
+	}
+	if enclosure.Status.String == downloadComplete || !enclosure.TotalBytes.Valid || enclosure.TotalBytes.Int64 == 0 {
+		return enclosure.Status.String
+	}
+	percent := enclosure.BytesDownloaded.Int64 * 100 / enclosure.TotalBytes.Int64
+	return fmt.Sprintf("%v (%d%%)", enclosure.Status.String, percent)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/scottyloveless/gator/internal/database"
+)
+
+func handlerExportFeed(s *state, cmd command, user database.User) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	format := fs.String("format", "rss", "output format, rss or atom")
+	limit := fs.Int("limit", 50, "number of posts to include")
+	publishURL := fs.String("url", "", "url the file will be published at, used as the feed's link")
+
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil || len(args) > 1 {
+		return fmt.Errorf("usage: %v [--format rss|atom] [--limit 50] [--url https://...] [file]", cmd.Name)
+	}
+
+	posts, err := s.db.GetPostsWithFeedForUser(context.Background(), database.GetPostsWithFeedForUserParams{
+		Name:  user.Name,
+		Limit: int32(*limit),
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := renderTimeline(*format, user, *publishURL, posts)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+
+	if err := os.WriteFile(args[0], data, 0644); err != nil {
+		return fmt.Errorf("error writing file: %w", err)
+	}
+
+	fmt.Printf("exported %d posts to %v\n", len(posts), args[0])
+	return nil
+}
+
+func (s *state) handlerAPITimeline(w http.ResponseWriter, r *http.Request, user database.User) {
+	format := r.PathValue("format")
+
+	limit := 50
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			respondWithError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+
+	posts, err := s.db.GetPostsWithFeedForUser(r.Context(), database.GetPostsWithFeedForUserParams{
+		Name:  user.Name,
+		Limit: int32(limit),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	selfURL := "http://" + r.Host + r.URL.Path
+	data, err := renderTimeline(format, user, selfURL, posts)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/"+format+"+xml")
+	w.Write(data)
+}
+
+func timelineTitle(user database.User) string {
+	return fmt.Sprintf("gator timeline for %v", user.Name)
+}
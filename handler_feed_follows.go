@@ -2,21 +2,44 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/scottyloveless/gator/internal/database"
 )
 
 func handlerAddFeed(s *state, cmd command, user database.User) error {
-	if len(cmd.Args) < 2 {
-		fmt.Println("not enough arguments. syntax is gator addfeed 'Hacker News' 'https://hackernews.com/rss'")
+	if len(cmd.Args) < 1 || len(cmd.Args) > 2 {
+		fmt.Println("wrong number of arguments. syntax is gator addfeed ['Hacker News'] 'https://hackernews.com/rss'")
 		os.Exit(1)
 	}
 
-	name := cmd.Args[0]
-	url := cmd.Args[1]
+	name := ""
+	rawURL := cmd.Args[0]
+	if len(cmd.Args) == 2 {
+		name = cmd.Args[0]
+		rawURL = cmd.Args[1]
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), discoverTimeout)
+	defer cancel()
+
+	url := resolveFeedURL(ctx, rawURL)
+
+	feedData, err := fetchFeed(ctx, url)
+	if err != nil {
+		return fmt.Errorf("couldn't read a feed from %v: %w", url, err)
+	}
+
+	if name == "" {
+		name = strings.TrimSpace(feedData.Channel.Title)
+	}
+	if name == "" {
+		name = url
+	}
 
 	params := database.CreateFeedParams{
 		CreatedAt: time.Now(),
@@ -31,6 +54,15 @@ func handlerAddFeed(s *state, cmd command, user database.User) error {
 		return err
 	}
 
+	if feedData.Channel.Link != "" {
+		if err := s.db.SetFeedSiteURL(context.Background(), database.SetFeedSiteURLParams{
+			ID:      feed.ID,
+			SiteUrl: sql.NullString{String: feedData.Channel.Link, Valid: true},
+		}); err != nil {
+			return err
+		}
+	}
+
 	newCmd := command{
 		Name: "follow",
 		Args: []string{url},
@@ -50,15 +82,21 @@ func handlerFeeds(s *state, cmd command) error {
 		return err
 	}
 
-	if len(feeds) == 0 {
-		return fmt.Errorf("no feeds found")
-	}
-
+	rows := make([]apiFeed, 0, len(feeds))
 	for _, feed := range feeds {
-		fmt.Printf("Name: %v\nURL: %v\nAdded by: %v\n", feed.Name, feed.Url, feed.Username.String)
+		rows = append(rows, toAPIFeed(feed))
 	}
 
-	return nil
+	return render(cmd.Output, rows, func() error {
+		if len(feeds) == 0 {
+			return fmt.Errorf("no feeds found")
+		}
+
+		for _, feed := range feeds {
+			fmt.Printf("Name: %v\nURL: %v\nAdded by: %v\n", feed.Name, feed.Url, feed.Username.String)
+		}
+		return nil
+	})
 }
 
 func handlerFollow(s *state, cmd command, user database.User) error {
@@ -71,6 +109,12 @@ func handlerFollow(s *state, cmd command, user database.User) error {
 	userId := user.ID
 
 	feed, err := s.db.GetFeedByURL(context.Background(), url)
+	if err != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), discoverTimeout)
+		url = resolveFeedURL(ctx, url)
+		cancel()
+		feed, err = s.db.GetFeedByURL(context.Background(), url)
+	}
 	if err != nil {
 		fmt.Printf("No feed found at URL: %v\n", cmd.Args[0])
 		os.Exit(1)
@@ -99,15 +143,21 @@ func handlerFollowing(s *state, cmd command, user database.User) error {
 		return err
 	}
 
-	if len(feeds) == 0 {
-		fmt.Printf("no feeds for user: %v", user.Name)
-	}
-
+	rows := make([]apiFollow, 0, len(feeds))
 	for _, feed := range feeds {
-		fmt.Printf("%v\n", feed.FeedName)
+		rows = append(rows, toAPIFollow(feed))
 	}
 
-	return nil
+	return render(cmd.Output, rows, func() error {
+		if len(feeds) == 0 {
+			fmt.Printf("no feeds for user: %v", user.Name)
+		}
+
+		for _, feed := range feeds {
+			fmt.Printf("%v\n", feed.FeedName)
+		}
+		return nil
+	})
 }
 
 func handlerUnfollow(s *state, cmd command, user database.User) error {
@@ -121,3 +171,16 @@ func handlerUnfollow(s *state, cmd command, user database.User) error {
 
 	return nil
 }
+
+func followedFeedID(s *state, user database.User, feed string) (int32, error) {
+	follows, err := s.db.GetFeedFollowsForUser(context.Background(), user.ID)
+	if err != nil {
+		return 0, err
+	}
+	for _, follow := range follows {
+		if follow.FeedUrl == feed || follow.FeedName == feed {
+			return follow.FeedID, nil
+		}
+	}
+	return 0, fmt.Errorf("you don't follow a feed named or at URL: %v", feed)
+}
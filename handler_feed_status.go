@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/scottyloveless/gator/internal/database"
+)
+
+type feedStatus struct {
+	Name                string     `json:"name"`
+	URL                 string     `json:"url"`
+	Health              string     `json:"health"`
+	ConsecutiveFailures int32      `json:"consecutive_failures"`
+	LastSuccessAt       *time.Time `json:"last_success_at"`
+	LastError           string     `json:"last_error,omitempty"`
+	NextFetchAt         *time.Time `json:"next_fetch_at"`
+}
+
+func handlerFeedStatus(s *state, cmd command) error {
+	feeds, err := s.db.ListFeeds(context.Background())
+	if err != nil {
+		return err
+	}
+
+	rows := make([]feedStatus, 0, len(feeds))
+	for _, feed := range feeds {
+		row := feedStatus{
+			Name:                feed.Name,
+			URL:                 feed.Url,
+			Health:              feedHealth(feed),
+			ConsecutiveFailures: feed.ConsecutiveFailures,
+			LastError:           feed.LastError.String,
+		}
+		if feed.LastSuccessAt.Valid {
+			row.LastSuccessAt = &feed.LastSuccessAt.Time
+		}
+		if feed.NextFetchAt.Valid {
+			row.NextFetchAt = &feed.NextFetchAt.Time
+		}
+		rows = append(rows, row)
+	}
+
+	return render(cmd.Output, rows, func() error {
+		if len(feeds) == 0 {
+			return fmt.Errorf("no feeds found")
+		}
+
+		for _, feed := range feeds {
+			fmt.Printf("Name:         %v\n", feed.Name)
+			fmt.Printf("URL:          %v\n", feed.Url)
+			fmt.Printf("Health:       %v\n", feedHealth(feed))
+			fmt.Printf("Last success: %v\n", formatNullTime(feed.LastSuccessAt))
+			if feed.LastError.Valid {
+				fmt.Printf("Last error:   %v\n", feed.LastError.String)
+			}
+			fmt.Printf("Next attempt: %v\n\n", nextAttempt(feed))
+		}
+		return nil
+	})
+}
+
+func feedHealth(feed database.ListFeedsRow) string {
+	switch {
+	case feed.ConsecutiveFailures > 0:
+		return fmt.Sprintf("failing (%d consecutive errors)", feed.ConsecutiveFailures)
+	case !feed.LastSuccessAt.Valid:
+		return "never fetched"
+	default:
+		return "ok"
+	}
+}
+
+func nextAttempt(feed database.ListFeedsRow) string {
+	if feed.NextFetchAt.Valid && feed.NextFetchAt.Time.After(time.Now()) {
+		return fmt.Sprintf("%v (backing off)", feed.NextFetchAt.Time.Format(time.DateTime))
+	}
+	return "next agg run"
+}
+
+func formatNullTime(t sql.NullTime) string {
+	if !t.Valid {
+		return "never"
+	}
+	return t.Time.Format(time.DateTime)
+}
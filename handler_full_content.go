@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/scottyloveless/gator/internal/database"
+)
+
+func handlerFullContent(s *state, cmd command, user database.User) error {
+	if len(cmd.Args) != 2 || (cmd.Args[1] != "on" && cmd.Args[1] != "off") {
+		return fmt.Errorf("usage: %v <feed_url> on|off", cmd.Name)
+	}
+
+	feedID, err := followedFeedID(s, user, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.SetFeedFullContent(context.Background(), database.SetFeedFullContentParams{
+		ID:               feedID,
+		FetchFullContent: cmd.Args[1] == "on",
+	}); err != nil {
+		return err
+	}
+
+	fmt.Printf("full content fetching turned %v for %v, it applies to posts collected from now on\n", cmd.Args[1], cmd.Args[0])
+	return nil
+}
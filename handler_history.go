@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+func handlerHistory(s *state, cmd command) error {
+	postID, err := parsePostID(cmd)
+	if err != nil {
+		return err
+	}
+
+	post, err := s.db.GetPost(context.Background(), postID)
+	if err != nil {
+		return fmt.Errorf("no post found with id %v", postID)
+	}
+
+	revisions, err := s.db.GetPostRevisions(context.Background(), postID)
+	if err != nil {
+		return err
+	}
+
+	if len(revisions) == 0 {
+		fmt.Printf("Post %v has not been edited since it was collected\n", postID)
+		return nil
+	}
+
+	fmt.Printf("%v\n", post.Title)
+	fmt.Printf("%d revisions, first seen %v\n", len(revisions), post.CreatedAt.Format(time.DateTime))
+
+	// Each revision holds the version that was replaced, so compare it with
+	// the next revision, or with the current post for the last one.
+	for i, revision := range revisions {
+		title, description, content := post.Title, post.Description, post.Content.String
+		if i+1 < len(revisions) {
+			next := revisions[i+1]
+			title, description, content = next.Title, next.Description, next.Content.String
+		}
+
+		fmt.Printf("\nRevision %d, replaced %v\n", i+1, revision.CreatedAt.Format(time.DateTime))
+		if revision.Title != title {
+			fmt.Printf(" * Title:          %v\n", wordDiff(revision.Title, title))
+		}
+		if revision.Description != description {
+			fmt.Printf(" * Description:    %v\n", wordDiff(htmlToText(revision.Description), htmlToText(description)))
+		}
+		if revision.Content.String != content {
+			fmt.Printf(" * Content:        %v\n", wordDiff(htmlToText(revision.Content.String), htmlToText(content)))
+		}
+	}
+
+	return nil
+}
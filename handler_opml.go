@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/scottyloveless/gator/internal/database"
+)
+
+func handlerImportOPML(s *state, cmd command, user database.User) error {
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("usage: %v <file>", cmd.Name)
+	}
+
+	data, err := os.ReadFile(cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("error reading file: %w", err)
+	}
+
+	var opml OPML
+	if err := xml.Unmarshal(data, &opml); err != nil {
+		return fmt.Errorf("error parsing OPML: %w", err)
+	}
+
+	follows, err := s.db.GetFeedFollowsForUser(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+	followed := make(map[int32]bool, len(follows))
+	for _, follow := range follows {
+		followed[follow.FeedID] = true
+	}
+
+	var created, followedCount, skipped, failed int
+	for _, sub := range opml.subscriptions() {
+		feed, err := s.db.GetFeedByURL(context.Background(), sub.URL)
+		if errors.Is(err, sql.ErrNoRows) {
+			name := sub.Name
+			if name == "" {
+				name = sub.URL
+			}
+			feed, err = s.db.CreateFeed(context.Background(), database.CreateFeedParams{
+				CreatedAt: time.Now(),
+				UpdatedAt: time.Now(),
+				Name:      name,
+				Url:       sub.URL,
+				UserID:    user.ID,
+			})
+			if err == nil && sub.SiteURL != "" {
+				err = s.db.SetFeedSiteURL(context.Background(), database.SetFeedSiteURLParams{
+					ID:      feed.ID,
+					SiteUrl: sql.NullString{String: sub.SiteURL, Valid: true},
+				})
+			}
+			if err == nil {
+				created++
+			}
+		}
+		if err != nil {
+			fmt.Printf("failed to import %v: %v\n", sub.URL, err)
+			failed++
+			continue
+		}
+
+		if followed[feed.ID] {
+			skipped++
+			continue
+		}
+
+		if _, err := s.db.CreateFeedFollow(context.Background(), database.CreateFeedFollowParams{
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			UserID:    user.ID,
+			FeedID:    feed.ID,
+		}); err != nil {
+			fmt.Printf("failed to follow %v: %v\n", sub.URL, err)
+			failed++
+			continue
+		}
+		followed[feed.ID] = true
+		followedCount++
+
+		if sub.Folder != "" {
+			if err := s.db.SetFeedFollowFolder(context.Background(), database.SetFeedFollowFolderParams{
+				UserID: user.ID,
+				FeedID: feed.ID,
+				Folder: sql.NullString{String: sub.Folder, Valid: true},
+			}); err != nil {
+				fmt.Printf("failed to set folder for %v: %v\n", sub.URL, err)
+			}
+		}
+	}
+
+	fmt.Printf("OPML import finished: %d created, %d followed, %d skipped, %d failed\n", created, followedCount, skipped, failed)
+
+	return nil
+}
+
+func handlerExportOPML(s *state, cmd command, user database.User) error {
+	if len(cmd.Args) > 1 {
+		return fmt.Errorf("usage: %v [file]", cmd.Name)
+	}
+
+	follows, err := s.db.GetFeedFollowsForUser(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	opml := buildOPML(fmt.Sprintf("gator subscriptions for %v", user.Name), follows)
+
+	data, err := xml.MarshalIndent(opml, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append([]byte(xml.Header), data...)
+	data = append(data, '\n')
+
+	if len(cmd.Args) == 0 {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+
+	if err := os.WriteFile(cmd.Args[0], data, 0644); err != nil {
+		return fmt.Errorf("error writing file: %w", err)
+	}
+
+	fmt.Printf("exported %d feeds to %v\n", len(follows), cmd.Args[0])
+	return nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"github.com/scottyloveless/gator/internal/database"
+)
+
+func handlerRead(s *state, cmd command, user database.User) error {
+	postID, err := parsePostID(cmd)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.MarkPostRead(context.Background(), database.MarkPostReadParams{UserID: user.ID, PostID: postID}); err != nil {
+		return fmt.Errorf("couldn't mark post %d read: %w", postID, err)
+	}
+
+	fmt.Printf("post %d marked read\n", postID)
+	return nil
+}
+
+func handlerUnread(s *state, cmd command, user database.User) error {
+	postID, err := parsePostID(cmd)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.MarkPostUnread(context.Background(), database.MarkPostUnreadParams{UserID: user.ID, PostID: postID}); err != nil {
+		return fmt.Errorf("couldn't mark post %d unread: %w", postID, err)
+	}
+
+	fmt.Printf("post %d marked unread\n", postID)
+	return nil
+}
+
+func handlerMarkAll(s *state, cmd command, user database.User) error {
+	if len(cmd.Args) < 1 || len(cmd.Args) > 2 {
+		return fmt.Errorf("usage: %v read|unread [feed_url]", cmd.Name)
+	}
+
+	feedURL := sql.NullString{}
+	if len(cmd.Args) == 2 {
+		feedURL = sql.NullString{String: cmd.Args[1], Valid: true}
+	}
+
+	var count int64
+	var err error
+	switch cmd.Args[0] {
+	case "read":
+		count, err = s.db.MarkAllPostsRead(context.Background(), database.MarkAllPostsReadParams{UserID: user.ID, FeedUrl: feedURL})
+	case "unread":
+		count, err = s.db.MarkAllPostsUnread(context.Background(), database.MarkAllPostsUnreadParams{UserID: user.ID, FeedUrl: feedURL})
+	default:
+		return fmt.Errorf("usage: %v read|unread [feed_url]", cmd.Name)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%d posts marked %v\n", count, cmd.Args[0])
+	return nil
+}
+
+func parsePostID(cmd command) (int32, error) {
+	if len(cmd.Args) != 1 {
+		return 0, fmt.Errorf("usage: %v <post_id>", cmd.Name)
+	}
+
+	id, err := strconv.ParseInt(cmd.Args[0], 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("enter a valid post id after %v", cmd.Name)
+	}
+
+	return int32(id), nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/scottyloveless/gator/internal/database"
+)
+
+func handlerRetention(s *state, cmd command) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	days := fs.String("days", "", "delete posts older than this many days, 0 keeps them forever, default uses the config")
+	maxPosts := fs.String("max-posts", "", "keep at most this many posts, 0 keeps all, default uses the config")
+
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil || len(args) != 1 {
+		return fmt.Errorf("usage: %v <feed_url> [--days n|default] [--max-posts n|default]", cmd.Name)
+	}
+
+	feed, err := s.db.GetFeedByURL(context.Background(), args[0])
+	if err != nil {
+		return fmt.Errorf("no feed found at URL: %v", args[0])
+	}
+
+	retentionDays, retentionMaxPosts := feed.RetentionDays, feed.RetentionMaxPosts
+	if *days != "" {
+		if retentionDays, err = parseRetention(*days); err != nil {
+			return fmt.Errorf("invalid --days: %w", err)
+		}
+	}
+	if *maxPosts != "" {
+		if retentionMaxPosts, err = parseRetention(*maxPosts); err != nil {
+			return fmt.Errorf("invalid --max-posts: %w", err)
+		}
+	}
+
+	if *days != "" || *maxPosts != "" {
+		if _, err := s.db.SetFeedRetention(context.Background(), database.SetFeedRetentionParams{
+			Url:               feed.Url,
+			RetentionDays:     retentionDays,
+			RetentionMaxPosts: retentionMaxPosts,
+		}); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Retention for %v\n", feed.Name)
+	fmt.Printf(" * Max age:        %v\n", describeRetention(retentionDays, s.cfg.RetentionDays, "days"))
+	fmt.Printf(" * Max posts:      %v\n", describeRetention(retentionMaxPosts, s.cfg.RetentionMaxPosts, "posts"))
+	return nil
+}
+
+func handlerPrune(s *state, cmd command) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "only report what would be deleted")
+
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil || len(args) != 0 {
+		return fmt.Errorf("usage: %v [--dry-run]", cmd.Name)
+	}
+
+	if *dryRun {
+		counts, err := s.db.CountPrunablePosts(context.Background(), database.CountPrunablePostsParams{
+			DefaultDays:     int32(s.cfg.RetentionDays),
+			DefaultMaxPosts: int32(s.cfg.RetentionMaxPosts),
+		})
+		if err != nil {
+			return err
+		}
+
+		var total int64
+		for _, count := range counts {
+			fmt.Printf("%v: %d posts\n", count.Name, count.Prunable)
+			total += count.Prunable
+		}
+		fmt.Printf("%d posts would be deleted\n", total)
+		return nil
+	}
+
+	count, err := prunePosts(context.Background(), s)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%d posts deleted\n", count)
+	return nil
+}
+
+// tombstoneDays is how long a pruned post's tombstone outlives the last time
+// its feed listed it. Feeds rarely bring an item back after dropping it for
+// that long.
+const tombstoneDays = 90
+
+// prunePosts deletes posts past their feed's retention. Saved posts and posts
+// that a follower hasn't read yet are always kept.
+func prunePosts(ctx context.Context, s *state) (int64, error) {
+	return s.db.PrunePosts(ctx, database.PrunePostsParams{
+		DefaultDays:     int32(s.cfg.RetentionDays),
+		DefaultMaxPosts: int32(s.cfg.RetentionMaxPosts),
+		TombstoneDays:   tombstoneDays,
+	})
+}
+
+func parseRetention(value string) (sql.NullInt32, error) {
+	if value == "default" {
+		return sql.NullInt32{}, nil
+	}
+
+	n, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || n < 0 {
+		return sql.NullInt32{}, fmt.Errorf("%q is not a number of 0 or more, or default", value)
+	}
+
+	return sql.NullInt32{Int32: int32(n), Valid: true}, nil
+}
+
+func describeRetention(value sql.NullInt32, fallback int, unit string) string {
+	source := ""
+	n := int(value.Int32)
+	if !value.Valid {
+		source = " (config default)"
+		n = fallback
+	}
+
+	if n == 0 {
+		return "unlimited" + source
+	}
+	return fmt.Sprintf("%d %v%v", n, unit, source)
+}
+
+func logPrune(s *state) {
+	count, err := prunePosts(context.Background(), s)
+	if err != nil {
+		log.Printf("Couldn't prune posts: %v", err)
+		return
+	}
+	if count > 0 {
+		log.Printf("Pruned %d old posts", count)
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/scottyloveless/gator/internal/database"
+)
+
+type filterRule struct {
+	ID      int32  `json:"id"`
+	Action  string `json:"action"`
+	Field   string `json:"field"`
+	Pattern string `json:"pattern"`
+	Feed    string `json:"feed,omitempty"`
+}
+
+const ruleUsage = "usage: %v add mute|highlight [title~|description~]<text> [--feed url|name] | list | remove <rule_id>"
+
+func handlerRule(s *state, cmd command, user database.User) error {
+	if len(cmd.Args) < 1 {
+		return fmt.Errorf(ruleUsage, cmd.Name)
+	}
+
+	args := command{Name: cmd.Name, Args: cmd.Args[1:], Output: cmd.Output}
+	list := cmd.Args[0] == "list" || cmd.Args[0] == "ls"
+	if cmd.Output != "" && !list {
+		return fmt.Errorf("--output only applies to %v list", cmd.Name)
+	}
+
+	switch cmd.Args[0] {
+	case "add":
+		return addRule(s, args, user)
+	case "list", "ls":
+		return listRules(s, args, user)
+	case "remove", "rm":
+		return removeRule(s, args, user)
+	default:
+		return fmt.Errorf(ruleUsage, cmd.Name)
+	}
+}
+
+func addRule(s *state, cmd command, user database.User) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	feed := fs.String("feed", "", "only apply the rule to this feed url or name")
+
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil || len(args) < 2 || (args[0] != "mute" && args[0] != "highlight") {
+		return fmt.Errorf(ruleUsage, cmd.Name)
+	}
+
+	field, pattern := parseRulePattern(strings.Join(args[1:], " "))
+	if pattern == "" {
+		return fmt.Errorf("rule text can't be empty")
+	}
+
+	params := database.CreateFilterRuleParams{
+		UserID:  user.ID,
+		Action:  args[0],
+		Field:   field,
+		Pattern: pattern,
+	}
+	if *feed != "" {
+		feedID, err := followedFeedID(s, user, *feed)
+		if err != nil {
+			return err
+		}
+		params.FeedID = sql.NullInt32{Int32: feedID, Valid: true}
+	}
+
+	rule, err := s.db.CreateFilterRule(context.Background(), params)
+	if err != nil {
+		return fmt.Errorf("couldn't add rule: %w", err)
+	}
+
+	fmt.Printf("rule %d added: %v\n", rule.ID, describeRule(rule.Action, rule.Field, rule.Pattern, *feed))
+	return nil
+}
+
+// parseRulePattern splits "title~text" and "description~text" into a field
+// and the text to look for. Anything else matches either field.
+func parseRulePattern(s string) (string, string) {
+	if field, pattern, ok := strings.Cut(s, "~"); ok {
+		switch field = strings.ToLower(strings.TrimSpace(field)); field {
+		case "title", "description", "any":
+			return field, strings.TrimSpace(pattern)
+		}
+	}
+	return "any", strings.TrimSpace(s)
+}
+
+func listRules(s *state, cmd command, user database.User) error {
+	if len(cmd.Args) > 0 {
+		return fmt.Errorf(ruleUsage, cmd.Name)
+	}
+
+	rules, err := s.db.GetFilterRulesForUser(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	rows := make([]filterRule, 0, len(rules))
+	for _, rule := range rules {
+		rows = append(rows, filterRule{
+			ID:      rule.ID,
+			Action:  rule.Action,
+			Field:   rule.Field,
+			Pattern: rule.Pattern,
+			Feed:    rule.FeedName.String,
+		})
+	}
+
+	return render(cmd.Output, rows, func() error {
+		if len(rules) == 0 {
+			fmt.Println("no rules found")
+			return nil
+		}
+
+		for _, rule := range rows {
+			fmt.Printf("%d: %v\n", rule.ID, describeRule(rule.Action, rule.Field, rule.Pattern, rule.Feed))
+		}
+		return nil
+	})
+}
+
+func describeRule(action, field, pattern, feed string) string {
+	where := "title or description"
+	if field != "any" {
+		where = field
+	}
+	description := fmt.Sprintf("%v posts with %q in the %v", action, pattern, where)
+	if feed != "" {
+		description += " from " + feed
+	}
+	return description
+}
+
+func removeRule(s *state, cmd command, user database.User) error {
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf(ruleUsage, cmd.Name)
+	}
+
+	id, err := strconv.ParseInt(cmd.Args[0], 10, 32)
+	if err != nil {
+		return fmt.Errorf("enter a valid rule id after %v remove", cmd.Name)
+	}
+
+	count, err := s.db.DeleteFilterRule(context.Background(), database.DeleteFilterRuleParams{ID: int32(id), UserID: user.ID})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("no rule found with id %d", id)
+	}
+
+	fmt.Printf("rule %d removed\n", id)
+	return nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/scottyloveless/gator/internal/database"
+)
+
+type savedPost struct {
+	ID          int32     `json:"id"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	Description string    `json:"description"`
+	PublishedAt time.Time `json:"published_at"`
+	Tags        []string  `json:"tags"`
+}
+
+func handlerSave(s *state, cmd command, user database.User) error {
+	if len(cmd.Args) < 1 {
+		return fmt.Errorf("usage: %v <post_id> [tag...]", cmd.Name)
+	}
+
+	postID, err := parsePostID(command{Name: cmd.Name, Args: cmd.Args[:1]})
+	if err != nil {
+		return err
+	}
+
+	tags := []string{}
+	for _, tag := range cmd.Args[1:] {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	if err := s.db.SavePost(context.Background(), database.SavePostParams{
+		UserID: user.ID,
+		PostID: postID,
+		Tags:   tags,
+	}); err != nil {
+		return fmt.Errorf("couldn't save post %d: %w", postID, err)
+	}
+
+	fmt.Printf("post %d saved\n", postID)
+	return nil
+}
+
+func handlerUnsave(s *state, cmd command, user database.User) error {
+	postID, err := parsePostID(cmd)
+	if err != nil {
+		return err
+	}
+
+	count, err := s.db.UnsavePost(context.Background(), database.UnsavePostParams{UserID: user.ID, PostID: postID})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("post %d isn't saved", postID)
+	}
+
+	fmt.Printf("post %d removed from saved posts\n", postID)
+	return nil
+}
+
+func handlerSaved(s *state, cmd command, user database.User) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	tag := fs.String("tag", "", "only show posts saved with this tag")
+
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil || len(args) > 0 {
+		return fmt.Errorf("usage: %v [--tag x]", cmd.Name)
+	}
+
+	posts, err := s.db.GetSavedPostsForUser(context.Background(), database.GetSavedPostsForUserParams{
+		UserID: user.ID,
+		Tag:    sql.NullString{String: *tag, Valid: *tag != ""},
+	})
+	if err != nil {
+		return err
+	}
+
+	rows := make([]savedPost, 0, len(posts))
+	for _, post := range posts {
+		rows = append(rows, savedPost{
+			ID:          post.ID,
+			Title:       post.Title,
+			URL:         post.Url,
+			Description: safeDescription(post.SafeHtml, post.Description),
+			PublishedAt: post.PublishedAt,
+			Tags:        post.Tags,
+		})
+	}
+
+	return render(cmd.Output, rows, func() error {
+		if len(posts) == 0 {
+			fmt.Println("no saved posts found")
+			return nil
+		}
+
+		for _, post := range posts {
+			printPost(database.Post{
+				ID:          post.ID,
+				Title:       post.Title,
+				Url:         post.Url,
+				Description: post.Description,
+				EditedAt:    post.EditedAt,
+				Excerpt:     post.Excerpt,
+			}, s.cfg.SummaryLength(), "")
+			if len(post.Tags) > 0 {
+				fmt.Printf(" * Tags:           %v\n", strings.Join(post.Tags, ", "))
+			}
+		}
+		return nil
+	})
+}
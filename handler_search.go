@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/scottyloveless/gator/internal/database"
+)
+
+type searchResult struct {
+	ID          int32     `json:"id"`
+	Title       string    `json:"title"`
+	Feed        string    `json:"feed"`
+	URL         string    `json:"url"`
+	PublishedAt time.Time `json:"published_at"`
+	Rank        float32   `json:"rank"`
+	Match       string    `json:"match"`
+}
+
+func handlerSearch(s *state, cmd command, user database.User) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	feed := fs.String("feed", "", "only search posts from this feed url or name")
+	since := fs.String("since", "", "only search posts published after this date or duration, e.g. 24h or 2024-01-31")
+	limit := fs.Int("limit", 10, "maximum number of results")
+
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil || len(args) == 0 {
+		return fmt.Errorf("usage: %v <query> [--feed url|name] [--since 24h] [--limit 10]", cmd.Name)
+	}
+
+	params := database.SearchPostsForUserParams{
+		Query:      strings.Join(args, " "),
+		Name:       user.Name,
+		Feed:       sql.NullString{String: *feed, Valid: *feed != ""},
+		MaxResults: int32(*limit),
+	}
+
+	if *since != "" {
+		sinceTime, err := parseTimeBound(*since, time.Now())
+		if err != nil {
+			return err
+		}
+		params.Since = sql.NullTime{Time: sinceTime, Valid: true}
+	}
+
+	results, err := s.db.SearchPostsForUser(context.Background(), params)
+	if err != nil {
+		return err
+	}
+
+	rows := make([]searchResult, 0, len(results))
+	for _, result := range results {
+		rows = append(rows, searchResult{
+			ID:          result.ID,
+			Title:       result.Title,
+			Feed:        result.FeedName,
+			URL:         result.Url,
+			PublishedAt: result.PublishedAt,
+			Rank:        result.Rank,
+			Match:       strings.Join(strings.Fields(result.Snippet), " "),
+		})
+	}
+
+	return render(cmd.Output, rows, func() error {
+		if len(results) == 0 {
+			fmt.Println("no posts found")
+			return nil
+		}
+
+		for _, result := range results {
+			fmt.Printf(" * ID:             %v\n", result.ID)
+			fmt.Printf(" * Title:          %v\n", result.Title)
+			fmt.Printf(" * Feed:           %v\n", result.FeedName)
+			fmt.Printf(" * Published:      %v\n", result.PublishedAt.Format(time.DateOnly))
+			fmt.Printf(" * URL:            %v\n", result.Url)
+			fmt.Printf(" * Match:          %v\n\n", strings.Join(strings.Fields(result.Snippet), " "))
+		}
+		return nil
+	})
+}
+
+// parseTimeBound accepts an absolute date or a duration before now,
+// including a "d" suffix for days
+func parseTimeBound(value string, now time.Time) (time.Time, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+
+	for _, layout := range []string{time.DateOnly, time.DateTime, time.RFC3339} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date or duration: %v", value)
+}
@@ -0,0 +1,421 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/scottyloveless/gator/internal/database"
+)
+
+const (
+	paneFeeds = iota
+	panePosts
+	paneReader
+)
+
+const tuiHelp = "tab/←→ switch pane  j/k move  enter open  r read/unread  o browser  m muted  R refresh  q quit"
+
+type tui struct {
+	s     *state
+	user  database.User
+	term  *terminal
+	limit int
+
+	// showMuted lists posts hidden by the user's mute rules too.
+	showMuted bool
+
+	feeds []database.GetFeedFollowsForUserRow
+	posts []database.GetPostsWithReadStateForUserRow
+
+	focus        int
+	feedCursor   int
+	feedTop      int
+	postCursor   int
+	postTop      int
+	readerScroll int
+	readerLines  []string
+	readerWidth  int
+	status       string
+}
+
+func handlerTUI(s *state, cmd command, user database.User) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	refresh := fs.Duration("refresh", 30*time.Second, "how often to reload posts collected by agg")
+	limit := fs.Int("limit", 200, "maximum number of posts to list")
+
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil || len(args) != 0 || *refresh <= 0 || *limit < 1 {
+		return fmt.Errorf("usage: %v [--refresh 30s] [--limit 200]", cmd.Name)
+	}
+
+	term, err := openTerminal()
+	if err != nil {
+		return err
+	}
+	defer term.close()
+
+	t := &tui{s: s, user: user, term: term, limit: *limit}
+	t.load()
+
+	keys := make(chan string)
+	go readKeys(keys)
+
+	ticker := time.NewTicker(*refresh)
+	defer ticker.Stop()
+
+	for {
+		t.draw()
+		select {
+		case key, ok := <-keys:
+			if !ok || key == keyQuit || key == "q" {
+				return nil
+			}
+			t.handleKey(key)
+		case <-ticker.C:
+			t.load()
+		case <-term.resized:
+		}
+	}
+}
+
+// load reloads feeds and posts from the database, keeping the selected feed
+// and post if they are still there.
+func (t *tui) load() {
+	ctx := context.Background()
+
+	selectedFeed := t.selectedFeedID()
+	feeds, err := t.s.db.GetFeedFollowsForUser(ctx, t.user.ID)
+	if err != nil {
+		t.status = fmt.Sprintf("couldn't load feeds: %v", err)
+		return
+	}
+	t.feeds = feeds
+	t.feedCursor = 0
+	for i, feed := range t.feeds {
+		if selectedFeed.Valid && feed.FeedID == selectedFeed.Int32 {
+			t.feedCursor = i + 1
+		}
+	}
+
+	t.loadPosts()
+}
+
+func (t *tui) loadPosts() {
+	selectedPost := int32(-1)
+	if post, ok := t.selectedPost(); ok {
+		selectedPost = post.ID
+	}
+
+	posts, err := t.s.db.GetPostsWithReadStateForUser(context.Background(), database.GetPostsWithReadStateForUserParams{
+		UserID:    t.user.ID,
+		FeedID:    t.selectedFeedID(),
+		ShowMuted: t.showMuted,
+		RowLimit:  int32(t.limit),
+	})
+	if err != nil {
+		t.status = fmt.Sprintf("couldn't load posts: %v", err)
+		return
+	}
+	t.posts = posts
+	t.status = fmt.Sprintf("%d posts, refreshed %v", len(posts), time.Now().Format(time.TimeOnly))
+	if t.showMuted {
+		t.status += ", showing muted posts"
+	}
+
+	t.postCursor = 0
+	for i, post := range t.posts {
+		if post.ID == selectedPost {
+			t.postCursor = i
+		}
+	}
+	if post, ok := t.selectedPost(); !ok || post.ID != selectedPost {
+		t.readerScroll = 0
+	}
+	t.readerLines = nil
+}
+
+// selectedFeedID is NULL when "All feeds", the first row, is selected.
+func (t *tui) selectedFeedID() sql.NullInt32 {
+	if t.feedCursor == 0 || t.feedCursor > len(t.feeds) {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: t.feeds[t.feedCursor-1].FeedID, Valid: true}
+}
+
+func (t *tui) selectedPost() (database.GetPostsWithReadStateForUserRow, bool) {
+	if t.postCursor < 0 || t.postCursor >= len(t.posts) {
+		return database.GetPostsWithReadStateForUserRow{}, false
+	}
+	return t.posts[t.postCursor], true
+}
+
+func (t *tui) handleKey(key string) {
+	_, rows := t.term.size()
+	page := max(rows-3, 1)
+
+	switch key {
+	case keyTab, keyRight, "l":
+		t.focus = min(t.focus+1, paneReader)
+	case keyBack, keyLeft, "h":
+		t.focus = max(t.focus-1, paneFeeds)
+	case keyDown, "j":
+		t.move(1)
+	case keyUp, "k":
+		t.move(-1)
+	case keyPgDn, " ":
+		t.move(page)
+	case keyPgUp, "b":
+		t.move(-page)
+	case "g":
+		t.move(-1 << 30)
+	case "G":
+		t.move(1 << 30)
+	case keyEnter:
+		switch t.focus {
+		case paneFeeds:
+			t.focus = panePosts
+		case panePosts:
+			t.focus = paneReader
+			t.setRead(true)
+		}
+	case "r":
+		if post, ok := t.selectedPost(); ok {
+			t.setRead(!post.IsRead)
+		}
+	case "o":
+		t.openSelected()
+	case "m":
+		t.showMuted = !t.showMuted
+		t.loadPosts()
+	case "R":
+		t.load()
+	}
+}
+
+func (t *tui) move(delta int) {
+	switch t.focus {
+	case paneFeeds:
+		cursor := clamp(t.feedCursor+delta, 0, len(t.feeds))
+		if cursor != t.feedCursor {
+			t.feedCursor = cursor
+			t.postCursor = 0
+			t.loadPosts()
+		}
+	case panePosts:
+		cursor := clamp(t.postCursor+delta, 0, len(t.posts)-1)
+		if cursor != t.postCursor {
+			t.postCursor = cursor
+			t.readerScroll = 0
+			t.readerLines = nil
+		}
+	case paneReader:
+		t.readerScroll = clamp(t.readerScroll+delta, 0, len(t.readerLines)-1)
+	}
+}
+
+func (t *tui) setRead(read bool) {
+	post, ok := t.selectedPost()
+	if !ok || post.IsRead == read {
+		return
+	}
+
+	var err error
+	if read {
+		err = t.s.db.MarkPostRead(context.Background(), database.MarkPostReadParams{UserID: t.user.ID, PostID: post.ID})
+	} else {
+		err = t.s.db.MarkPostUnread(context.Background(), database.MarkPostUnreadParams{UserID: t.user.ID, PostID: post.ID})
+	}
+	if err != nil {
+		t.status = fmt.Sprintf("couldn't update post %d: %v", post.ID, err)
+		return
+	}
+	t.posts[t.postCursor].IsRead = read
+}
+
+func (t *tui) openSelected() {
+	post, ok := t.selectedPost()
+	if !ok || post.Url == "" {
+		return
+	}
+	if err := openBrowser(post.Url); err != nil {
+		t.status = fmt.Sprintf("couldn't open browser: %v", err)
+		return
+	}
+	t.setRead(true)
+	t.status = "opened " + post.Url
+}
+
+// openBrowser starts $BROWSER, or the platform's default opener, without
+// waiting for it so the tui stays responsive.
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch browser := os.Getenv("BROWSER"); {
+	case browser != "":
+		cmd = exec.Command(browser, url)
+	case runtime.GOOS == "darwin":
+		cmd = exec.Command("open", url)
+	case runtime.GOOS == "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+func (t *tui) draw() {
+	cols, rows := t.term.size()
+	height := max(rows-2, 1)
+
+	feedsWidth := clamp(cols/5, 12, 30)
+	postsWidth := clamp(cols*3/10, 20, 60)
+	readerWidth := max(cols-feedsWidth-postsWidth-2, 10)
+
+	feedLines := t.feedPane(feedsWidth, height)
+	postLines := t.postPane(postsWidth, height)
+	readerLines := t.readerPane(readerWidth, height)
+
+	var b strings.Builder
+	b.WriteString("\x1b[H")
+	b.WriteString("\x1b[7m" + fit(" gator - "+t.user.Name, cols) + "\x1b[0m\x1b[K\r\n")
+	for i := 0; i < height; i++ {
+		b.WriteString(feedLines[i] + "│" + postLines[i] + "│" + readerLines[i] + "\x1b[K\r\n")
+	}
+	b.WriteString(fit(" "+t.status+"  |  "+tuiHelp, cols) + "\x1b[K")
+	os.Stdout.WriteString(b.String())
+}
+
+func (t *tui) feedPane(width, height int) []string {
+	labels := []string{"All feeds"}
+	for _, feed := range t.feeds {
+		label := feed.FeedName
+		if feed.Folder.Valid {
+			label = feed.Folder.String + "/" + label
+		}
+		labels = append(labels, label)
+	}
+
+	t.feedTop = scrollTo(t.feedCursor, t.feedTop, height)
+	return paneLines(labels, t.feedTop, t.feedCursor, t.focus == paneFeeds, width, height)
+}
+
+func (t *tui) postPane(width, height int) []string {
+	labels := make([]string, len(t.posts))
+	for i, post := range t.posts {
+		marker := "● "
+		if post.IsRead {
+			marker = "  "
+		}
+		if post.Highlight.Valid {
+			marker = marker[:len(marker)-1] + "★"
+		}
+		labels[i] = marker + post.Title
+	}
+	if len(labels) == 0 {
+		labels = append(labels, "  no posts yet, run gator agg")
+	}
+
+	t.postTop = scrollTo(t.postCursor, t.postTop, height)
+	return paneLines(labels, t.postTop, t.postCursor, t.focus == panePosts, width, height)
+}
+
+func (t *tui) readerPane(width, height int) []string {
+	if t.readerLines == nil || t.readerWidth != width {
+		t.readerLines = []string{}
+		t.readerWidth = width
+		if post, ok := t.selectedPost(); ok {
+			body := articleHTML(database.Post{
+				Description: post.Description,
+				Content:     post.Content,
+				FullContent: post.FullContent,
+			})
+
+			t.readerLines = append(t.readerLines, wrapText(post.Title, width-1)...)
+			t.readerLines = append(t.readerLines,
+				post.FeedName+" - "+post.PublishedAt.Format(time.DateTime),
+				post.Url,
+				"",
+			)
+			t.readerLines = append(t.readerLines, wrapText(htmlToText(body), width-1)...)
+		}
+	}
+
+	lines := make([]string, height)
+	for i := range lines {
+		line := ""
+		if n := t.readerScroll + i; n < len(t.readerLines) {
+			line = " " + t.readerLines[n]
+		}
+		lines[i] = fit(line, width)
+	}
+	return lines
+}
+
+// paneLines renders a list with the cursor row highlighted, reversed when
+// the pane has focus and bold otherwise.
+func paneLines(labels []string, top, cursor int, focused bool, width, height int) []string {
+	lines := make([]string, height)
+	for i := range lines {
+		n := top + i
+		if n >= len(labels) {
+			lines[i] = fit("", width)
+			continue
+		}
+
+		line := fit(" "+labels[n], width)
+		if n == cursor {
+			if focused {
+				line = "\x1b[7m" + line + "\x1b[0m"
+			} else {
+				line = "\x1b[1m" + line + "\x1b[0m"
+			}
+		}
+		lines[i] = line
+	}
+	return lines
+}
+
+// scrollTo returns the first visible row so that cursor stays on screen.
+func scrollTo(cursor, top, height int) int {
+	if cursor < top {
+		return cursor
+	}
+	if cursor >= top+height {
+		return cursor - height + 1
+	}
+	return top
+}
+
+// fit pads or truncates s to exactly width runes on a single line.
+func fit(s string, width int) string {
+	s = strings.Map(func(r rune) rune {
+		if r < ' ' || r == 0x7f {
+			return ' '
+		}
+		return r
+	}, s)
+	n := utf8.RuneCountInString(s)
+	if n > width {
+		runes := []rune(s)
+		return string(runes[:max(width-1, 0)]) + "…"
+	}
+	return s + strings.Repeat(" ", width-n)
+}
+
+func clamp(n, low, high int) int {
+	if high < low {
+		return low
+	}
+	return min(max(n, low), high)
+}
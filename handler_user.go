@@ -10,6 +10,13 @@ import (
 	"github.com/scottyloveless/gator/internal/database"
 )
 
+type userListing struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	Current   bool      `json:"current"`
+}
+
 func handlerLogin(s *state, cmd command) error {
 	if len(cmd.Args) <= 0 {
 		return fmt.Errorf("no username found. Please add username after login command")
@@ -75,19 +82,30 @@ func handlerUsers(s *state, cmd command) error {
 	}
 	currentUserLoggedIn := s.cfg.CurrentUserName
 
-	if len(users) == 0 {
-		return fmt.Errorf("no users found")
+	rows := make([]userListing, 0, len(users))
+	for _, user := range users {
+		rows = append(rows, userListing{
+			ID:        user.ID,
+			Name:      user.Name,
+			CreatedAt: user.CreatedAt,
+			Current:   user.Name == currentUserLoggedIn,
+		})
 	}
 
-	for _, user := range users {
-		if user.Name == currentUserLoggedIn {
-			fmt.Printf("* %v (current)\n", user.Name)
-		} else {
-			fmt.Printf("* %v\n", user.Name)
+	return render(cmd.Output, rows, func() error {
+		if len(users) == 0 {
+			return fmt.Errorf("no users found")
 		}
-	}
 
-	return nil
+		for _, user := range users {
+			if user.Name == currentUserLoggedIn {
+				fmt.Printf("* %v (current)\n", user.Name)
+			} else {
+				fmt.Printf("* %v\n", user.Name)
+			}
+		}
+		return nil
+	})
 }
 
 func printUser(user database.User) {
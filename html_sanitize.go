@@ -0,0 +1,212 @@
+package main
+
+import (
+	"database/sql"
+	"html"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/scottyloveless/gator/internal/database"
+)
+
+// storedExcerptLength is how much plain text is kept per post, listings
+// shorten it further.
+const storedExcerptLength = 500
+
+// allowedTags maps the elements kept by sanitizeHTML to the attributes they
+// may keep. Everything else is dropped, keeping its text unless it is one of
+// skippedTags.
+var allowedTags = map[string][]string{
+	"a": {"href", "title"}, "abbr": {"title"}, "b": nil, "blockquote": {"cite"},
+	"br": nil, "code": nil, "dd": nil, "del": nil, "div": nil, "dl": nil, "dt": nil,
+	"em": nil, "figcaption": nil, "figure": nil, "h1": nil, "h2": nil, "h3": nil,
+	"h4": nil, "h5": nil, "h6": nil, "hr": nil, "i": nil,
+	"img": {"src", "alt", "title", "width", "height"}, "ins": nil, "li": nil, "ol": nil,
+	"p": nil, "pre": nil, "q": {"cite"}, "s": nil, "small": nil, "span": nil,
+	"strong": nil, "sub": nil, "sup": nil, "table": nil, "tbody": nil,
+	"td": {"colspan", "rowspan"}, "tfoot": nil, "th": {"colspan", "rowspan"},
+	"thead": nil, "tr": nil, "u": nil, "ul": nil,
+}
+
+var voidTags = map[string]bool{"br": true, "hr": true, "img": true}
+
+var impliedEndTags = map[string]bool{
+	"li": true, "p": true, "dt": true, "dd": true, "tr": true, "td": true, "th": true,
+}
+
+var urlAttrs = map[string]bool{"href": true, "src": true, "cite": true}
+
+// trackerMarkers identify images that only exist to report that a post was read.
+var trackerMarkers = []string{
+	"feeds.feedburner.com/~r/",
+	"feedburner.com/~ff/",
+	"stats.wordpress.com/",
+	"pixel.wp.com/",
+	"doubleclick.net/",
+	"google-analytics.com/",
+	"/pixel.gif",
+	"/pixel.png",
+	"/open.gif",
+	"/beacon",
+}
+
+// sanitizeHTML rewrites post HTML so it is safe to show in a browser: only
+// formatting elements and a few attributes survive, links and images must be
+// http(s), mailto or relative, and tracking pixels are removed.
+func sanitizeHTML(s string) string {
+	var b strings.Builder
+	var open []string
+	skip := 0
+
+	for _, token := range tokenizeHTML(s) {
+		if token.tag == "" {
+			if skip == 0 {
+				b.WriteString(html.EscapeString(html.UnescapeString(token.text)))
+			}
+			continue
+		}
+
+		if skippedTags[token.tag] {
+			if token.closing {
+				skip = max(skip-1, 0)
+			} else if !token.selfClosing {
+				skip++
+			}
+			continue
+		}
+		if skip > 0 {
+			continue
+		}
+
+		allowed, ok := allowedTags[token.tag]
+		if !ok {
+			continue
+		}
+
+		if token.closing {
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] != token.tag {
+					continue
+				}
+				for j := len(open) - 1; j >= i; j-- {
+					b.WriteString("</" + open[j] + ">")
+				}
+				open = open[:i]
+				break
+			}
+			continue
+		}
+
+		if token.tag == "img" && isTrackingPixel(token) {
+			continue
+		}
+
+		// An unclosed <li> or <p> ends where the next sibling starts.
+		if impliedEndTags[token.tag] && len(open) > 0 && open[len(open)-1] == token.tag {
+			b.WriteString("</" + token.tag + ">")
+			open = open[:len(open)-1]
+		}
+
+		b.WriteString("<" + token.tag)
+		for _, name := range allowed {
+			value := strings.TrimSpace(token.attr(name))
+			if value == "" || (urlAttrs[name] && !isSafeURL(value)) {
+				continue
+			}
+			b.WriteString(" " + name + `="` + html.EscapeString(value) + `"`)
+		}
+		if token.tag == "a" {
+			b.WriteString(` rel="nofollow noopener noreferrer"`)
+		}
+		b.WriteString(">")
+
+		if !voidTags[token.tag] {
+			open = append(open, token.tag)
+		}
+	}
+
+	for i := len(open) - 1; i >= 0; i-- {
+		b.WriteString("</" + open[i] + ">")
+	}
+	return b.String()
+}
+
+// isSafeURL accepts relative URLs and the http, https and mailto schemes,
+// rejecting javascript:, data: and anything that hides a scheme in
+// whitespace or control characters.
+func isSafeURL(raw string) bool {
+	cleaned := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, raw)
+
+	u, err := url.Parse(cleaned)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+		return true
+	default:
+		return false
+	}
+}
+
+func isTrackingPixel(img htmlToken) bool {
+	if tiny(img.attr("width")) || tiny(img.attr("height")) {
+		return true
+	}
+
+	style := strings.ReplaceAll(strings.ToLower(img.attr("style")), " ", "")
+	if strings.Contains(style, "width:1px") || strings.Contains(style, "height:1px") ||
+		strings.Contains(style, "width:0") || strings.Contains(style, "height:0") ||
+		strings.Contains(style, "display:none") {
+		return true
+	}
+
+	src := strings.ToLower(img.attr("src"))
+	for _, marker := range trackerMarkers {
+		if strings.Contains(src, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func tiny(dimension string) bool {
+	n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(dimension), "px"))
+	return err == nil && n <= 1
+}
+
+// safeDescription prefers the sanitized HTML stored when the post was
+// collected and sanitizes posts collected before that on the fly.
+func safeDescription(safeHTML sql.NullString, description string) string {
+	if safeHTML.Valid {
+		return safeHTML.String
+	}
+	return sanitizeHTML(description)
+}
+
+// articleHTML is the most complete version of a post we have: the extracted
+// web page, then the feed's full content, then its description.
+func articleHTML(post database.Post) string {
+	switch {
+	case post.FullContent.Valid:
+		return post.FullContent.String
+	case post.Content.Valid:
+		return post.Content.String
+	default:
+		return post.Description
+	}
+}
+
+// postExcerpt returns at most n runes of a post's plain-text summary.
+func postExcerpt(post database.Post, n int) string {
+	if post.Excerpt.Valid && n <= storedExcerptLength {
+		return truncateWords(post.Excerpt.String, n)
+	}
+	return excerpt(post.Description, n)
+}
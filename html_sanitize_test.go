@@ -0,0 +1,262 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizeHTML(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"plain text", "just text", "just text"},
+		{"allowed markup", `<p>Hello <b>world</b></p>`, `<p>Hello <b>world</b></p>`},
+		{"uppercase tags", `<P>Hi <STRONG>there</STRONG></P>`, `<p>Hi <strong>there</strong></p>`},
+		{"link", `<a href="https://example.com/a?b=1&amp;c=2">x</a>`, `<a href="https://example.com/a?b=1&amp;c=2" rel="nofollow noopener noreferrer">x</a>`},
+		{"relative link", `<a href="/post/1">x</a>`, `<a href="/post/1" rel="nofollow noopener noreferrer">x</a>`},
+		{"mailto", `<a href="mailto:me@example.com">x</a>`, `<a href="mailto:me@example.com" rel="nofollow noopener noreferrer">x</a>`},
+
+		// scripts hidden in urls
+		{"javascript", `<a href="javascript:alert(1)">x</a>`, `<a rel="nofollow noopener noreferrer">x</a>`},
+		{"javascript mixed case", `<a href="JaVaScRiPt:alert(1)">x</a>`, `<a rel="nofollow noopener noreferrer">x</a>`},
+		{"javascript leading space", `<a href="  javascript:alert(1)">x</a>`, `<a rel="nofollow noopener noreferrer">x</a>`},
+		{"javascript with tab", "<a href=\"java\tscript:alert(1)\">x</a>", `<a rel="nofollow noopener noreferrer">x</a>`},
+		{"javascript with newline", "<a href=\"java\nscript:alert(1)\">x</a>", `<a rel="nofollow noopener noreferrer">x</a>`},
+		{"javascript with encoded tab", `<a href="java&#x09;script:alert(1)">x</a>`, `<a rel="nofollow noopener noreferrer">x</a>`},
+		{"javascript decimal entities", `<a href="&#106;&#97;vascript:alert(1)">x</a>`, `<a rel="nofollow noopener noreferrer">x</a>`},
+		{"javascript hex entities", `<a href="&#x6A;avascript&#x3A;alert(1)">x</a>`, `<a rel="nofollow noopener noreferrer">x</a>`},
+		{"javascript named colon", `<a href="javascript&colon;alert(1)">x</a>`, `<a rel="nofollow noopener noreferrer">x</a>`},
+		{"javascript nul byte", "<a href=\"java\x00script:alert(1)\">x</a>", `<a rel="nofollow noopener noreferrer">x</a>`},
+		{"javascript unquoted", `<a href=javascript:alert(1)>x</a>`, `<a rel="nofollow noopener noreferrer">x</a>`},
+		{"javascript single quotes", `<a href='javascript:alert(1)'>x</a>`, `<a rel="nofollow noopener noreferrer">x</a>`},
+		{"vbscript", `<a href="vbscript:msgbox(1)">x</a>`, `<a rel="nofollow noopener noreferrer">x</a>`},
+		{"data image", `<img src="data:image/svg+xml;base64,PHN2Zz4=">`, `<img>`},
+		{"data mixed case", `<img src="DaTa:text/html,<script>alert(1)</script>">`, `<img>`},
+		{"javascript in cite", `<blockquote cite="javascript:alert(1)">q</blockquote>`, `<blockquote>q</blockquote>`},
+
+		// attributes
+		{"event handlers dropped", `<img src="/a.png" onerror="alert(1)" alt="A">`, `<img src="/a.png" alt="A">`},
+		{"style and class dropped", `<p style="color:red" class="x" id="y">t</p>`, `<p>t</p>`},
+		{"unquoted attributes", `<img src=/a.png alt=A width=10>`, `<img src="/a.png" alt="A" width="10">`},
+		{"attribute without value", `<td colspan>x</td>`, `<td>x</td>`},
+		{"attribute breaking out of quotes", `<a title='x" onclick="alert(1)'>x</a>`, `<a title="x&#34; onclick=&#34;alert(1)" rel="nofollow noopener noreferrer">x</a>`},
+		{"attribute entities", `<img alt="AT&amp;T &lt;3">`, `<img alt="AT&amp;T &lt;3">`},
+
+		// elements whose content must not leak through
+		{"script", `a<script>alert(1)</script>b`, `ab`},
+		{"script uppercase", `a<SCRIPT>alert(1)</ScRiPt>b`, `ab`},
+		{"script with markup inside", `a<script>document.write("<b>x</b>")</script>b`, `ab`},
+		{"unclosed script", `a<script>alert(1)`, `a`},
+		{"style", `<style>p { color: red }</style><p>x</p>`, `<p>x</p>`},
+		{"style hiding a tag", `<style><img src=x onerror=alert(1)></style>ok`, `ok`},
+		{"svg", `<svg><script>alert(1)</script><text>hi</text></svg>ok`, `ok`},
+		{"svg onload", `<svg onload="alert(1)"/>ok`, `ok`},
+		{"math", `<math><mi>x</mi></math>ok`, `ok`},
+		{"iframe", `<iframe src="https://evil.example"></iframe>ok`, `ok`},
+		{"object", `<object data="x.swf">fallback</object>ok`, `ok`},
+		{"template", `<template><img src=x onerror=alert(1)></template>ok`, `ok`},
+		{"textarea", `<textarea></textarea><script>alert(1)</script></textarea>ok`, `ok`},
+		{"comment", `<!-- <script>alert(1)</script> -->ok`, `ok`},
+		{"unclosed comment", `ok<!-- <script>alert(1)</script>`, `ok`},
+		{"conditional comment", `<!--[if IE]><script>alert(1)</script><![endif]-->ok`, `ok`},
+		{"doctype", `<!DOCTYPE html>ok`, `ok`},
+		{"processing instruction", `<?xml version="1.0"?>ok`, `ok`},
+		{"cdata", `<![CDATA[<script>alert(1)</script>]]>`, `&lt;script&gt;alert(1)&lt;/script&gt;`},
+
+		// unknown elements keep their text
+		{"unknown element", `<font color="red">red</font>`, `red`},
+		{"form elements", `<form action="/x"><input value="y">text</form>`, `text`},
+
+		// broken markup
+		{"unclosed tags", `<p><b>bold<i>both`, `<p><b>bold<i>both</i></b></p>`},
+		{"unterminated tag", `<p>x</p><img src="/a.png" onerror="alert(1)"`, `<p>x</p><img src="/a.png">`},
+		{"misnested", `<b><i>x</b>y</i>`, `<b><i>x</i></b>y`},
+		{"stray end tag", `x</div></b>y`, `xy`},
+		{"implied paragraph end", `<p>one<p>two`, `<p>one</p><p>two</p>`},
+		{"implied list item end", `<ul><li>one<li>two</ul>`, `<ul><li>one</li><li>two</li></ul>`},
+		{"stray less than", `a < b and c<3`, `a &lt; b and c&lt;3`},
+		{"less than before slash", `1 </ 2`, `1 &lt;/ 2`},
+		{"self closing", `a<br/>b<hr />c`, `a<br>b<hr>c`},
+
+		// entities in text
+		{"escaped ampersand", `AT&amp;T`, `AT&amp;T`},
+		{"bare ampersand", `AT&T`, `AT&amp;T`},
+		{"escaped markup stays text", `&lt;script&gt;alert(1)&lt;/script&gt;`, `&lt;script&gt;alert(1)&lt;/script&gt;`},
+		{"double escaped", `&amp;lt;b&amp;gt;`, `&amp;lt;b&amp;gt;`},
+		{"named and numeric entities", `caf&eacute; &#8212; &#x263A;`, `café — ☺`},
+		{"quotes", `"quoted" 'text'`, `&#34;quoted&#34; &#39;text&#39;`},
+
+		// tracking pixels
+		{"one pixel image", `<p>x<img src="https://t.example/a.gif" width="1" height="1"></p>`, `<p>x</p>`},
+		{"zero pixel image", `<img src="https://t.example/a.gif" width="0px">`, ``},
+		{"hidden image", `<img src="https://t.example/a.gif" style="display: none">`, ``},
+		{"tiny styled image", `<img src="https://t.example/a.gif" style="width: 1px; height: 1px">`, ``},
+		{"feedburner pixel", `<img src="http://feeds.feedburner.com/~r/example/~4/abc">`, ``},
+		{"wordpress stats", `<img src="https://stats.wordpress.com/b.gif?host=x">`, ``},
+		{"normal image", `<img src="https://example.com/photo.jpg" width="640">`, `<img src="https://example.com/photo.jpg" width="640">`},
+	}
+
+	for _, tt := range tests {
+		if got := sanitizeHTML(tt.input); got != tt.want {
+			t.Errorf("%v: sanitizeHTML(%q)\n got %q\nwant %q", tt.name, tt.input, got, tt.want)
+		}
+	}
+}
+
+// TestSanitizeHTMLNeverEmitsScripts runs nasty inputs through the sanitizer
+// and checks the output for anything a browser would execute.
+func TestSanitizeHTMLNeverEmitsScripts(t *testing.T) {
+	inputs := []string{
+		`<scr<script>ipt>alert(1)</script>`,
+		`<<script>script>alert(1)<</script>/script>`,
+		`<img src="x" onerror="alert(1)"//>`,
+		`<img/src="x"/onerror="alert(1)">`,
+		`<a href="javas&#99;ript:alert(1)">x</a>`,
+		`<a href="&#0000106&#0000097&#0000118&#0000097&#0000115&#0000099&#0000114&#0000105&#0000112&#0000116&#0000058alert(1)">x</a>`,
+		`<a href="jav&#x0A;ascript:alert(1)">x</a>`,
+		`<a href=" &#14; javascript:alert(1)">x</a>`,
+		`<svg><a xlink:href="javascript:alert(1)"><text>x</text></a></svg>`,
+		`<math><a href="javascript:alert(1)">x</a></math>`,
+		`<style>@import "javascript:alert(1)";</style>`,
+		`<div style="background:url(javascript:alert(1))">x</div>`,
+		`<iframe srcdoc="<script>alert(1)</script>"></iframe>`,
+		`<base href="javascript:alert(1)//">`,
+		`<meta http-equiv="refresh" content="0;url=javascript:alert(1)">`,
+		`<object data="javascript:alert(1)"></object>`,
+		`<embed src="javascript:alert(1)">`,
+		`<form><button formaction="javascript:alert(1)">x</button></form>`,
+		`<a href="data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==">x</a>`,
+		`<p title="</p><script>alert(1)</script>">x</p>`,
+	}
+
+	for _, input := range inputs {
+		got := strings.ToLower(sanitizeHTML(input))
+		for _, bad := range []string{"<script", "javascript:", "data:", "onerror", "onload", "style=", "<iframe", "<svg", "<math", "<base", "<meta", "<object", "<embed", "<form", "<button", "xlink"} {
+			if strings.Contains(got, bad) {
+				t.Errorf("sanitizeHTML(%q) = %q, contains %q", input, got, bad)
+			}
+		}
+	}
+}
+
+func TestIsSafeURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://example.com/", true},
+		{"http://example.com/a?b=c#d", true},
+		{"HTTPS://EXAMPLE.COM/", true},
+		{"mailto:me@example.com", true},
+		{"/relative/path", true},
+		{"relative/path", true},
+		{"?query", true},
+		{"#fragment", true},
+		{"//example.com/protocol-relative", true},
+		{"javascript:alert(1)", false},
+		{"JAVASCRIPT:alert(1)", false},
+		{" javascript:alert(1)", false},
+		{"java\tscript:alert(1)", false},
+		{"java\nscript:alert(1)", false},
+		{"java\rscript:alert(1)", false},
+		{"java\x00script:alert(1)", false},
+		{"\x01javascript:alert(1)", false},
+		{"java\x7fscript:alert(1)", false},
+		{"data:text/html,hi", false},
+		{"vbscript:msgbox(1)", false},
+		{"file:///etc/passwd", false},
+		{"ftp://example.com/", false},
+		{"http://[::1", false},
+	}
+
+	for _, tt := range tests {
+		if got := isSafeURL(tt.url); got != tt.want {
+			t.Errorf("isSafeURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestTokenizeHTML(t *testing.T) {
+	tokens := tokenizeHTML(`a<IMG SRC=x.png alt='A "B"' data-x="1 &amp; 2" hidden/>b</P >`)
+	if len(tokens) != 4 {
+		t.Fatalf("got %d tokens, want 4: %+v", len(tokens), tokens)
+	}
+
+	if tokens[0].text != "a" || tokens[2].text != "b" {
+		t.Errorf("text tokens = %q, %q", tokens[0].text, tokens[2].text)
+	}
+
+	img := tokens[1]
+	if img.tag != "img" || img.closing || !img.selfClosing {
+		t.Errorf("img token = %+v", img)
+	}
+	for name, want := range map[string]string{"src": "x.png", "alt": `A "B"`, "data-x": "1 & 2", "hidden": ""} {
+		if got := img.attr(name); got != want {
+			t.Errorf("img attr %v = %q, want %q", name, got, want)
+		}
+	}
+
+	if end := tokens[3]; end.tag != "p" || !end.closing {
+		t.Errorf("end token = %+v", end)
+	}
+}
+
+func TestTokenizeHTMLRawText(t *testing.T) {
+	tokens := tokenizeHTML(`<script>if (a < b && c) { x = "</b>" }</SCRIPT>after`)
+	var tags []string
+	for _, token := range tokens {
+		if token.tag != "" {
+			tags = append(tags, token.tag)
+		}
+	}
+	if strings.Join(tags, ",") != "script,script" {
+		t.Errorf("tags = %v, want only the script start and end tags", tags)
+	}
+	if last := tokens[len(tokens)-1]; last.text != "after" {
+		t.Errorf("last token = %+v, want text after the script", last)
+	}
+}
+
+func TestHTMLToText(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"plain &amp; simple", "plain & simple"},
+		{"<p>one</p><p>two</p>", "one\n\ntwo"},
+		{"<ul><li>a<li>b</ul>", "• a\n• b"},
+		{"a<br>b", "a\nb"},
+		{"<p>x<script>alert(1)</script><style>p{}</style>y</p>", "xy"},
+		{"<b>AT&amp;T</b> &lt;3", "AT&T <3"},
+		{"  lots   of\n\n\n\nspace  ", "lots   of\n\nspace"},
+	}
+
+	for _, tt := range tests {
+		if got := htmlToText(tt.input); got != tt.want {
+			t.Errorf("htmlToText(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	tests := []struct {
+		input string
+		n     int
+		want  string
+	}{
+		{"<p>short</p>", 20, "short"},
+		{"<p>one two</p><p>three four</p>", 100, "one two three four"},
+		{"one two three four five", 15, "one two three…"},
+		{"one, two, three, four", 12, "one, two…"},
+		{"supercalifragilistic", 10, "supercali…"},
+		{"héllo wörld ünïcode", 12, "héllo wörld…"},
+	}
+
+	for _, tt := range tests {
+		if got := excerpt(tt.input, tt.n); got != tt.want {
+			t.Errorf("excerpt(%q, %d) = %q, want %q", tt.input, tt.n, got, tt.want)
+		}
+	}
+}
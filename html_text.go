@@ -0,0 +1,342 @@
+package main
+
+import (
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+// htmlToken is either a run of text (tag is empty) or a start or end tag.
+// Text is kept escaped as it appeared in the document.
+type htmlToken struct {
+	text        string
+	tag         string
+	closing     bool
+	selfClosing bool
+	attrs       []htmlAttr
+}
+
+// htmlAttr holds an attribute with its value already unescaped.
+type htmlAttr struct {
+	name  string
+	value string
+}
+
+func (t htmlToken) attr(name string) string {
+	for _, a := range t.attrs {
+		if a.name == name {
+			return a.value
+		}
+	}
+	return ""
+}
+
+// rawTextTags hold text that must not be parsed for tags.
+var rawTextTags = map[string]bool{"script": true, "style": true, "textarea": true, "title": true}
+
+// tokenizeHTML splits a fragment of HTML into text and tags. It is forgiving
+// in the way feed content needs: stray "<" is text, unclosed tags run to the
+// end, and comments, doctypes and processing instructions are dropped.
+func tokenizeHTML(s string) []htmlToken {
+	var tokens []htmlToken
+	text := func(t string) {
+		if t != "" {
+			tokens = append(tokens, htmlToken{text: t})
+		}
+	}
+
+	for len(s) > 0 {
+		start := strings.IndexByte(s, '<')
+		if start < 0 {
+			text(s)
+			break
+		}
+		text(s[:start])
+		s = s[start:]
+
+		switch {
+		case strings.HasPrefix(s, "<!--"):
+			end := strings.Index(s, "-->")
+			if end < 0 {
+				return tokens
+			}
+			s = s[end+3:]
+			continue
+		case strings.HasPrefix(s, "<![CDATA["):
+			end := strings.Index(s, "]]>")
+			if end < 0 {
+				text(html.EscapeString(s[9:]))
+				return tokens
+			}
+			text(html.EscapeString(s[9:end]))
+			s = s[end+3:]
+			continue
+		case strings.HasPrefix(s, "<!") || strings.HasPrefix(s, "<?"):
+			end := strings.IndexByte(s, '>')
+			if end < 0 {
+				return tokens
+			}
+			s = s[end+1:]
+			continue
+		}
+
+		if len(s) < 2 || !(isASCIILetter(s[1]) || (s[1] == '/' && len(s) > 2 && isASCIILetter(s[2]))) {
+			text("&lt;")
+			s = s[1:]
+			continue
+		}
+
+		token, rest := parseTag(s)
+		tokens = append(tokens, token)
+		s = rest
+
+		if rawTextTags[token.tag] && !token.closing && !token.selfClosing {
+			end := strings.Index(strings.ToLower(s), "</"+token.tag)
+			if end < 0 {
+				end = len(s)
+			}
+			text(html.EscapeString(s[:end]))
+			s = s[end:]
+		}
+	}
+	return tokens
+}
+
+// parseTag reads the tag at the start of s, which begins with "<".
+func parseTag(s string) (htmlToken, string) {
+	var token htmlToken
+	i := 1
+	if s[i] == '/' {
+		token.closing = true
+		i++
+	}
+
+	start := i
+	for i < len(s) && !isTagSpace(s[i]) && s[i] != '>' && s[i] != '/' {
+		i++
+	}
+	token.tag = strings.ToLower(s[start:i])
+
+	for i < len(s) {
+		for i < len(s) && isTagSpace(s[i]) {
+			i++
+		}
+		if i >= len(s) {
+			break
+		}
+		if s[i] == '>' {
+			return token, s[i+1:]
+		}
+		if s[i] == '/' {
+			token.selfClosing = true
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(s) && !isTagSpace(s[i]) && s[i] != '=' && s[i] != '>' && s[i] != '/' {
+			i++
+		}
+		name := strings.ToLower(s[start:i])
+		if name == "" {
+			i++
+			continue
+		}
+
+		for i < len(s) && isTagSpace(s[i]) {
+			i++
+		}
+		value := ""
+		if i < len(s) && s[i] == '=' {
+			i++
+			for i < len(s) && isTagSpace(s[i]) {
+				i++
+			}
+			if i < len(s) && (s[i] == '"' || s[i] == '\'') {
+				quote := s[i]
+				end := strings.IndexByte(s[i+1:], quote)
+				if end < 0 {
+					value = s[i+1:]
+					i = len(s)
+				} else {
+					value = s[i+1 : i+1+end]
+					i += end + 2
+				}
+			} else {
+				start := i
+				for i < len(s) && !isTagSpace(s[i]) && s[i] != '>' {
+					i++
+				}
+				value = s[start:i]
+			}
+		}
+		token.attrs = append(token.attrs, htmlAttr{name: name, value: html.UnescapeString(value)})
+	}
+	return token, ""
+}
+
+func isASCIILetter(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func isTagSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// blockTags start a new line, paragraphTags are also separated by a blank line.
+var (
+	blockTags = map[string]bool{
+		"br": true, "div": true, "li": true, "ul": true, "ol": true, "tr": true,
+		"table": true, "section": true, "article": true, "header": true, "footer": true,
+		"figure": true, "figcaption": true, "dt": true, "dd": true, "hr": true,
+	}
+	paragraphTags = map[string]bool{
+		"p": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+		"blockquote": true, "pre": true,
+	}
+	skippedTags = map[string]bool{
+		"script": true, "style": true, "head": true, "noscript": true, "template": true,
+		"title": true, "textarea": true, "iframe": true, "object": true, "svg": true, "math": true,
+	}
+)
+
+// htmlToText renders post HTML as plain text for the terminal: tags are
+// dropped, block elements become line breaks and entities are decoded.
+// Plain text descriptions keep their own line breaks.
+func htmlToText(s string) string {
+	if !strings.Contains(s, "<") {
+		return cleanLines(html.UnescapeString(s))
+	}
+
+	var b strings.Builder
+	inPre := false
+	skip := 0
+	for _, token := range tokenizeHTML(s) {
+		if token.tag == "" {
+			if skip == 0 {
+				writeText(&b, html.UnescapeString(token.text), inPre)
+			}
+			continue
+		}
+
+		if skippedTags[token.tag] {
+			if token.closing {
+				skip = max(skip-1, 0)
+			} else if !token.selfClosing {
+				skip++
+			}
+			continue
+		}
+		if skip > 0 {
+			continue
+		}
+
+		switch {
+		case token.tag == "pre":
+			inPre = !token.closing
+			b.WriteString("\n\n")
+		case paragraphTags[token.tag]:
+			b.WriteString("\n\n")
+		case token.tag == "li" && !token.closing:
+			b.WriteString("\n• ")
+		case blockTags[token.tag]:
+			b.WriteString("\n")
+		}
+	}
+
+	return cleanLines(b.String())
+}
+
+func writeText(b *strings.Builder, text string, inPre bool) {
+	if inPre {
+		b.WriteString(text)
+		return
+	}
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		if text != "" {
+			b.WriteString(" ")
+		}
+		return
+	}
+	if text[0] == ' ' || text[0] == '\n' || text[0] == '\t' {
+		b.WriteString(" ")
+	}
+	b.WriteString(strings.Join(fields, " "))
+	if last := text[len(text)-1]; last == ' ' || last == '\n' || last == '\t' {
+		b.WriteString(" ")
+	}
+}
+
+// cleanLines trims every line and keeps at most one blank line in a row.
+func cleanLines(s string) string {
+	var lines []string
+	blank := true
+	for _, line := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		line = strings.TrimRight(strings.TrimLeft(line, " "), " \t")
+		if line == "" {
+			if !blank {
+				lines = append(lines, "")
+			}
+			blank = true
+			continue
+		}
+		lines = append(lines, line)
+		blank = false
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// wrapText breaks text into lines of at most width runes, splitting on spaces
+// and cutting words that don't fit on a line of their own.
+func wrapText(text string, width int) []string {
+	if width < 1 {
+		width = 1
+	}
+
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			for utf8.RuneCountInString(word) > width {
+				if line != "" {
+					lines = append(lines, line)
+					line = ""
+				}
+				runes := []rune(word)
+				lines = append(lines, string(runes[:width]))
+				word = string(runes[width:])
+			}
+			switch {
+			case line == "":
+				line = word
+			case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width:
+				line += " " + word
+			default:
+				lines = append(lines, line)
+				line = word
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// excerpt turns HTML into a single paragraph of plain text cut at a word
+// boundary so that it is at most n runes long.
+func excerpt(s string, n int) string {
+	return truncateWords(strings.Join(strings.Fields(htmlToText(s)), " "), n)
+}
+
+func truncateWords(text string, n int) string {
+	if utf8.RuneCountInString(text) <= n {
+		return text
+	}
+
+	runes := []rune(text)
+	cut := string(runes[:n-1])
+	if i := strings.LastIndexByte(cut, ' '); i > len(cut)/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,.;:") + "…"
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
 const configFileName = ".gatorconfig.json"
@@ -11,6 +13,11 @@ const configFileName = ".gatorconfig.json"
 type Config struct {
 	DBurl           string `json:"db_url"`
 	CurrentUserName string `json:"current_user_name"`
+	DownloadDir     string `json:"download_dir,omitempty"`
+	// Default retention for feeds without their own, zero keeps posts forever.
+	RetentionDays     int `json:"retention_days,omitempty"`
+	RetentionMaxPosts int `json:"retention_max_posts,omitempty"`
+	ExcerptLength     int `json:"excerpt_length,omitempty"`
 }
 
 func Read() (Config, error) {
@@ -41,6 +48,34 @@ func getConfigFilePath() (string, error) {
 	return homeDir + "/" + configFileName, nil
 }
 
+func (c Config) DownloadPath() (string, error) {
+	if c.DownloadDir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("error finding home directory: %v", err)
+		}
+		return filepath.Join(homeDir, "gator", "downloads"), nil
+	}
+
+	if dir, ok := strings.CutPrefix(c.DownloadDir, "~/"); ok {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("error finding home directory: %v", err)
+		}
+		return filepath.Join(homeDir, dir), nil
+	}
+
+	return c.DownloadDir, nil
+}
+
+// SummaryLength is how many characters of each post listings show.
+func (c Config) SummaryLength() int {
+	if c.ExcerptLength <= 0 {
+		return 280
+	}
+	return c.ExcerptLength
+}
+
 func (c Config) SetUser(username string) error {
 	c.CurrentUserName = username
 	write(c)
@@ -7,6 +7,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
@@ -21,11 +22,11 @@ WITH inserted_feed_follow AS (
 		$3,
 		$4
 		)
-	RETURNING id, created_at, updated_at, user_id, feed_id
+	RETURNING id, created_at, updated_at, user_id, feed_id, folder
 )
 
 SELECT
-    inserted_feed_follow.id, inserted_feed_follow.created_at, inserted_feed_follow.updated_at, inserted_feed_follow.user_id, inserted_feed_follow.feed_id,
+    inserted_feed_follow.id, inserted_feed_follow.created_at, inserted_feed_follow.updated_at, inserted_feed_follow.user_id, inserted_feed_follow.feed_id, inserted_feed_follow.folder,
     feeds.name AS feed_name,
     users.name AS user_name
 FROM inserted_feed_follow
@@ -48,6 +49,7 @@ type CreateFeedFollowRow struct {
 	UpdatedAt time.Time
 	UserID    uuid.UUID
 	FeedID    int32
+	Folder    sql.NullString
 	FeedName  string
 	UserName  string
 }
@@ -66,6 +68,7 @@ func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowPara
 		&i.UpdatedAt,
 		&i.UserID,
 		&i.FeedID,
+		&i.Folder,
 		&i.FeedName,
 		&i.UserName,
 	)
@@ -76,23 +79,28 @@ const getFeedFollowsForUser = `-- name: GetFeedFollowsForUser :many
 SELECT
     feed_follows.user_id,
     feed_follows.feed_id,
+    feed_follows.folder,
     users.name,
     feeds.url AS feed_url,
-    feeds.name AS feed_name
+    feeds.name AS feed_name,
+    feeds.site_url AS feed_site_url
 FROM feed_follows
 INNER JOIN users
     ON feed_follows.user_id = users.id
 INNER JOIN feeds
     ON feed_follows.feed_id = feeds.id
 WHERE feed_follows.user_id = $1
+ORDER BY feed_follows.folder NULLS FIRST, feeds.name
 `
 
 type GetFeedFollowsForUserRow struct {
-	UserID   uuid.UUID
-	FeedID   int32
-	Name     string
-	FeedUrl  string
-	FeedName string
+	UserID      uuid.UUID
+	FeedID      int32
+	Folder      sql.NullString
+	Name        string
+	FeedUrl     string
+	FeedName    string
+	FeedSiteUrl sql.NullString
 }
 
 func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) ([]GetFeedFollowsForUserRow, error) {
@@ -107,9 +115,11 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) (
 		if err := rows.Scan(
 			&i.UserID,
 			&i.FeedID,
+			&i.Folder,
 			&i.Name,
 			&i.FeedUrl,
 			&i.FeedName,
+			&i.FeedSiteUrl,
 		); err != nil {
 			return nil, err
 		}
@@ -124,6 +134,25 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) (
 	return items, nil
 }
 
+const setFeedFollowFolder = `-- name: SetFeedFollowFolder :exec
+UPDATE feed_follows
+SET folder = $3,
+updated_at = NOW()
+WHERE user_id = $1
+AND feed_id = $2
+`
+
+type SetFeedFollowFolderParams struct {
+	UserID uuid.UUID
+	FeedID int32
+	Folder sql.NullString
+}
+
+func (q *Queries) SetFeedFollowFolder(ctx context.Context, arg SetFeedFollowFolderParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedFollowFolder, arg.UserID, arg.FeedID, arg.Folder)
+	return err
+}
+
 const unfollow = `-- name: Unfollow :exec
 DELETE FROM feed_follows
 WHERE user_id = (SELECT id FROM users WHERE users.name = $1)
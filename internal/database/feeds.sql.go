@@ -13,6 +13,71 @@ import (
 	"github.com/google/uuid"
 )
 
+const claimFeedsToFetch = `-- name: ClaimFeedsToFetch :many
+UPDATE feeds
+SET last_fetched_at = NOW(),
+updated_at = NOW()
+WHERE id IN (
+    SELECT id FROM feeds
+    WHERE (next_fetch_at IS NULL OR next_fetch_at <= NOW())
+    AND (
+        last_fetched_at IS NULL
+        OR last_fetched_at < NOW() - make_interval(secs => $1::float8)
+    )
+    ORDER BY last_fetched_at ASC NULLS FIRST
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, consecutive_failures, last_error, last_success_at, next_fetch_at, site_url, is_podcast, retention_days, retention_max_posts, fetch_full_content
+`
+
+type ClaimFeedsToFetchParams struct {
+	StaleSeconds float64
+	BatchSize    int32
+}
+
+func (q *Queries) ClaimFeedsToFetch(ctx context.Context, arg ClaimFeedsToFetchParams) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, claimFeedsToFetch, arg.StaleSeconds, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.ConsecutiveFailures,
+			&i.LastError,
+			&i.LastSuccessAt,
+			&i.NextFetchAt,
+			&i.SiteUrl,
+			&i.IsPodcast,
+			&i.RetentionDays,
+			&i.RetentionMaxPosts,
+			&i.FetchFullContent,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createFeed = `-- name: CreateFeed :one
 INSERT INTO feeds (created_at, updated_at, name, url, user_id)
 VALUES (
@@ -22,7 +87,7 @@ VALUES (
 	$4,
 	$5
 	)
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, consecutive_failures, last_error, last_success_at, next_fetch_at, site_url, is_podcast, retention_days, retention_max_posts, fetch_full_content
 `
 
 type CreateFeedParams struct {
@@ -50,12 +115,23 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.ConsecutiveFailures,
+		&i.LastError,
+		&i.LastSuccessAt,
+		&i.NextFetchAt,
+		&i.SiteUrl,
+		&i.IsPodcast,
+		&i.RetentionDays,
+		&i.RetentionMaxPosts,
+		&i.FetchFullContent,
 	)
 	return i, err
 }
 
 const getFeedByURL = `-- name: GetFeedByURL :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, consecutive_failures, last_error, last_success_at, next_fetch_at, site_url, is_podcast, retention_days, retention_max_posts, fetch_full_content
 FROM feeds
 WHERE url = $1
 `
@@ -71,47 +147,48 @@ func (q *Queries) GetFeedByURL(ctx context.Context, url string) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
-	)
-	return i, err
-}
-
-const getNextFeedToFetch = `-- name: GetNextFeedToFetch :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
-ORDER BY last_fetched_at ASC NULLS FIRST
-LIMIT 1
-`
-
-func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
-	row := q.db.QueryRowContext(ctx, getNextFeedToFetch)
-	var i Feed
-	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Name,
-		&i.Url,
-		&i.UserID,
-		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.ConsecutiveFailures,
+		&i.LastError,
+		&i.LastSuccessAt,
+		&i.NextFetchAt,
+		&i.SiteUrl,
+		&i.IsPodcast,
+		&i.RetentionDays,
+		&i.RetentionMaxPosts,
+		&i.FetchFullContent,
 	)
 	return i, err
 }
 
 const listFeeds = `-- name: ListFeeds :many
-SELECT feeds.id, feeds.created_at, feeds.updated_at, feeds.name, feeds.url, feeds.user_id, feeds.last_fetched_at, users.name AS username
+SELECT feeds.id, feeds.created_at, feeds.updated_at, feeds.name, feeds.url, feeds.user_id, feeds.last_fetched_at, feeds.etag, feeds.last_modified, feeds.consecutive_failures, feeds.last_error, feeds.last_success_at, feeds.next_fetch_at, feeds.site_url, feeds.is_podcast, feeds.retention_days, feeds.retention_max_posts, feeds.fetch_full_content, users.name AS username
 FROM feeds
 LEFT JOIN users
 ON feeds.user_id = users.id
 `
 
 type ListFeedsRow struct {
-	ID            int32
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	Name          string
-	Url           string
-	UserID        uuid.UUID
-	LastFetchedAt sql.NullTime
-	Username      sql.NullString
+	ID                  int32
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Name                string
+	Url                 string
+	UserID              uuid.UUID
+	LastFetchedAt       sql.NullTime
+	Etag                sql.NullString
+	LastModified        sql.NullString
+	ConsecutiveFailures int32
+	LastError           sql.NullString
+	LastSuccessAt       sql.NullTime
+	NextFetchAt         sql.NullTime
+	SiteUrl             sql.NullString
+	IsPodcast           bool
+	RetentionDays       sql.NullInt32
+	RetentionMaxPosts   sql.NullInt32
+	FetchFullContent    bool
+	Username            sql.NullString
 }
 
 func (q *Queries) ListFeeds(ctx context.Context) ([]ListFeedsRow, error) {
@@ -131,6 +208,17 @@ func (q *Queries) ListFeeds(ctx context.Context) ([]ListFeedsRow, error) {
 			&i.Url,
 			&i.UserID,
 			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.ConsecutiveFailures,
+			&i.LastError,
+			&i.LastSuccessAt,
+			&i.NextFetchAt,
+			&i.SiteUrl,
+			&i.IsPodcast,
+			&i.RetentionDays,
+			&i.RetentionMaxPosts,
+			&i.FetchFullContent,
 			&i.Username,
 		); err != nil {
 			return nil, err
@@ -146,16 +234,47 @@ func (q *Queries) ListFeeds(ctx context.Context) ([]ListFeedsRow, error) {
 	return items, nil
 }
 
+const markFeedFailed = `-- name: MarkFeedFailed :exec
+UPDATE feeds
+SET updated_at = NOW(),
+consecutive_failures = consecutive_failures + 1,
+last_error = $2,
+next_fetch_at = NOW() + LEAST(INTERVAL '1 minute' * POWER(2, LEAST(consecutive_failures, 11)), INTERVAL '1 day')
+WHERE id = $1
+`
+
+type MarkFeedFailedParams struct {
+	ID        int32
+	LastError sql.NullString
+}
+
+func (q *Queries) MarkFeedFailed(ctx context.Context, arg MarkFeedFailedParams) error {
+	_, err := q.db.ExecContext(ctx, markFeedFailed, arg.ID, arg.LastError)
+	return err
+}
+
 const markFeedFetched = `-- name: MarkFeedFetched :one
 UPDATE feeds
 SET last_fetched_at = NOW(),
-updated_at = NOW()
+updated_at = NOW(),
+etag = $2,
+last_modified = $3,
+last_success_at = NOW(),
+consecutive_failures = 0,
+last_error = NULL,
+next_fetch_at = NULL
 WHERE id = $1
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, consecutive_failures, last_error, last_success_at, next_fetch_at, site_url, is_podcast, retention_days, retention_max_posts, fetch_full_content
 `
 
-func (q *Queries) MarkFeedFetched(ctx context.Context, id int32) (Feed, error) {
-	row := q.db.QueryRowContext(ctx, markFeedFetched, id)
+type MarkFeedFetchedParams struct {
+	ID           int32
+	Etag         sql.NullString
+	LastModified sql.NullString
+}
+
+func (q *Queries) MarkFeedFetched(ctx context.Context, arg MarkFeedFetchedParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, markFeedFetched, arg.ID, arg.Etag, arg.LastModified)
 	var i Feed
 	err := row.Scan(
 		&i.ID,
@@ -165,6 +284,71 @@ func (q *Queries) MarkFeedFetched(ctx context.Context, id int32) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.ConsecutiveFailures,
+		&i.LastError,
+		&i.LastSuccessAt,
+		&i.NextFetchAt,
+		&i.SiteUrl,
+		&i.IsPodcast,
+		&i.RetentionDays,
+		&i.RetentionMaxPosts,
+		&i.FetchFullContent,
 	)
 	return i, err
 }
+
+const setFeedFullContent = `-- name: SetFeedFullContent :exec
+UPDATE feeds
+SET fetch_full_content = $2,
+updated_at = NOW()
+WHERE id = $1
+`
+
+type SetFeedFullContentParams struct {
+	ID               int32
+	FetchFullContent bool
+}
+
+func (q *Queries) SetFeedFullContent(ctx context.Context, arg SetFeedFullContentParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedFullContent, arg.ID, arg.FetchFullContent)
+	return err
+}
+
+const setFeedPodcast = `-- name: SetFeedPodcast :execrows
+UPDATE feeds
+SET is_podcast = $2,
+updated_at = NOW()
+WHERE url = $1
+`
+
+type SetFeedPodcastParams struct {
+	Url       string
+	IsPodcast bool
+}
+
+func (q *Queries) SetFeedPodcast(ctx context.Context, arg SetFeedPodcastParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setFeedPodcast, arg.Url, arg.IsPodcast)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setFeedSiteURL = `-- name: SetFeedSiteURL :exec
+UPDATE feeds
+SET site_url = $2,
+updated_at = NOW()
+WHERE id = $1
+`
+
+type SetFeedSiteURLParams struct {
+	ID      int32
+	SiteUrl sql.NullString
+}
+
+func (q *Queries) SetFeedSiteURL(ctx context.Context, arg SetFeedSiteURLParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedSiteURL, arg.ID, arg.SiteUrl)
+	return err
+}
@@ -11,14 +11,37 @@ import (
 	"github.com/google/uuid"
 )
 
+type Download struct {
+	ID              int32
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	EnclosureID     int32
+	Path            string
+	Status          string
+	BytesDownloaded int64
+	TotalBytes      sql.NullInt64
+	LastError       sql.NullString
+}
+
 type Feed struct {
-	ID            int32
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	Name          string
-	Url           string
-	UserID        uuid.UUID
-	LastFetchedAt sql.NullTime
+	ID                  int32
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Name                string
+	Url                 string
+	UserID              uuid.UUID
+	LastFetchedAt       sql.NullTime
+	Etag                sql.NullString
+	LastModified        sql.NullString
+	ConsecutiveFailures int32
+	LastError           sql.NullString
+	LastSuccessAt       sql.NullTime
+	NextFetchAt         sql.NullTime
+	SiteUrl             sql.NullString
+	IsPodcast           bool
+	RetentionDays       sql.NullInt32
+	RetentionMaxPosts   sql.NullInt32
+	FetchFullContent    bool
 }
 
 type FeedFollow struct {
@@ -27,22 +50,91 @@ type FeedFollow struct {
 	UpdatedAt time.Time
 	UserID    uuid.UUID
 	FeedID    int32
+	Folder    sql.NullString
+}
+
+type FilterRule struct {
+	ID        int32
+	CreatedAt time.Time
+	UserID    uuid.UUID
+	Action    string
+	Field     string
+	Pattern   string
+	FeedID    sql.NullInt32
+}
+
+type FullContentQueue struct {
+	PostID        int32
+	QueuedAt      time.Time
+	Attempts      int32
+	NextAttemptAt time.Time
+	LastError     sql.NullString
 }
 
 type Post struct {
+	ID              int32
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Title           string
+	Url             string
+	Description     string
+	PublishedAt     time.Time
+	FeedID          int32
+	Guid            sql.NullString
+	GuidIsPermalink sql.NullBool
+	Author          sql.NullString
+	Content         sql.NullString
+	EditedAt        sql.NullTime
+	SafeHtml        sql.NullString
+	Excerpt         sql.NullString
+	FullContent     sql.NullString
+}
+
+type PostCategory struct {
+	PostID int32
+	Name   string
+}
+
+type PostEnclosure struct {
+	ID     int32
+	PostID int32
+	Url    string
+	Type   sql.NullString
+	Length sql.NullInt64
+}
+
+type PostRevision struct {
 	ID          int32
 	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	PostID      int32
 	Title       string
-	Url         string
 	Description string
-	PublishedAt time.Time
-	FeedID      int32
+	Content     sql.NullString
 }
 
-type User struct {
-	ID        uuid.UUID
+type PostRead struct {
+	UserID uuid.UUID
+	PostID int32
+	ReadAt time.Time
+}
+
+type PrunedPost struct {
+	FeedID     int32
+	PostKey    string
+	LastSeenAt time.Time
+}
+
+type SavedPost struct {
+	UserID    uuid.UUID
+	PostID    int32
 	CreatedAt time.Time
-	UpdatedAt time.Time
-	Name      string
+	Tags      []string
+}
+
+type User struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	Name       string
+	ApiKeyHash sql.NullString
 }
@@ -7,45 +7,275 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 )
 
-const createPost = `-- name: CreatePost :exec
-INSERT INTO posts (created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES (
+const adoptPostGUID = `-- name: AdoptPostGUID :execrows
+UPDATE posts
+SET guid = $3,
+guid_is_permalink = $4
+WHERE feed_id = $1
+AND url = $2
+AND guid IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM posts existing
+    WHERE existing.feed_id = $1
+    AND existing.guid = $3
+)
+`
+
+type AdoptPostGUIDParams struct {
+	FeedID          int32
+	Url             string
+	Guid            sql.NullString
+	GuidIsPermalink sql.NullBool
+}
+
+func (q *Queries) AdoptPostGUID(ctx context.Context, arg AdoptPostGUIDParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, adoptPostGUID,
+		arg.FeedID,
+		arg.Url,
+		arg.Guid,
+		arg.GuidIsPermalink,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const browsePostsForUser = `-- name: BrowsePostsForUser :many
+SELECT
+    posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.guid, posts.guid_is_permalink, posts.author, posts.content, posts.edited_at, posts.safe_html, posts.excerpt, posts.full_content,
+    (
+        SELECT filter_rules.pattern FROM filter_rules
+        WHERE filter_rules.user_id = users.id
+        AND filter_rules.action = 'highlight'
+        AND (filter_rules.feed_id IS NULL OR filter_rules.feed_id = posts.feed_id)
+        AND filter_rule_matches(filter_rules.field, filter_rules.pattern, posts.title, posts.description)
+        ORDER BY filter_rules.id
+        LIMIT 1
+    ) AS highlight
+FROM users
+INNER JOIN feed_follows
+    ON users.id = feed_follows.user_id
+INNER JOIN feeds
+    ON feed_follows.feed_id = feeds.id
+INNER JOIN posts
+    ON feeds.id = posts.feed_id
+WHERE users.name = $1
+AND (NOT $2::bool OR NOT EXISTS (
+    SELECT 1 FROM post_reads
+    WHERE post_reads.post_id = posts.id
+    AND post_reads.user_id = users.id
+))
+AND ($3::text IS NULL OR feeds.url = $3 OR feeds.name = $3)
+AND ($4::timestamp IS NULL OR posts.published_at >= $4)
+AND ($5::timestamp IS NULL OR posts.published_at < $5)
+AND ($6::text IS NULL OR strpos(lower(posts.title), lower($6)) > 0)
+AND ($7::int IS NULL OR CASE $8::text
+    WHEN 'oldest' THEN (posts.published_at, posts.id) > (SELECT published_at, id FROM posts WHERE id = $7)
+    WHEN 'title' THEN (posts.title, posts.id) > (SELECT title, id FROM posts WHERE id = $7)
+    ELSE (posts.published_at, posts.id) < (SELECT published_at, id FROM posts WHERE id = $7)
+END)
+AND ($9::bool OR NOT EXISTS (
+    SELECT 1 FROM filter_rules
+    WHERE filter_rules.user_id = users.id
+    AND filter_rules.action = 'mute'
+    AND (filter_rules.feed_id IS NULL OR filter_rules.feed_id = posts.feed_id)
+    AND filter_rule_matches(filter_rules.field, filter_rules.pattern, posts.title, posts.description)
+))
+ORDER BY
+    CASE WHEN $8 = 'oldest' THEN posts.published_at END ASC,
+    CASE WHEN $8 = 'title' THEN posts.title END ASC,
+    CASE WHEN $8 IN ('oldest', 'title') THEN posts.id END ASC,
+    posts.published_at DESC,
+    posts.id DESC
+LIMIT $10
+OFFSET $11
+`
+
+type BrowsePostsForUserParams struct {
+	Name       string
+	UnreadOnly bool
+	Feed       sql.NullString
+	Since      sql.NullTime
+	Before     sql.NullTime
+	Title      sql.NullString
+	AfterID    sql.NullInt32
+	Sort       string
+	ShowMuted  bool
+	RowLimit   int32
+	RowOffset  int32
+}
+
+type BrowsePostsForUserRow struct {
+	ID              int32
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Title           string
+	Url             string
+	Description     string
+	PublishedAt     time.Time
+	FeedID          int32
+	Guid            sql.NullString
+	GuidIsPermalink sql.NullBool
+	Author          sql.NullString
+	Content         sql.NullString
+	EditedAt        sql.NullTime
+	SafeHtml        sql.NullString
+	Excerpt         sql.NullString
+	FullContent     sql.NullString
+	Highlight       sql.NullString
+}
+
+func (q *Queries) BrowsePostsForUser(ctx context.Context, arg BrowsePostsForUserParams) ([]BrowsePostsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, browsePostsForUser,
+		arg.Name,
+		arg.UnreadOnly,
+		arg.Feed,
+		arg.Since,
+		arg.Before,
+		arg.Title,
+		arg.AfterID,
+		arg.Sort,
+		arg.ShowMuted,
+		arg.RowLimit,
+		arg.RowOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BrowsePostsForUserRow
+	for rows.Next() {
+		var i BrowsePostsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Guid,
+			&i.GuidIsPermalink,
+			&i.Author,
+			&i.Content,
+			&i.EditedAt,
+			&i.SafeHtml,
+			&i.Excerpt,
+			&i.FullContent,
+			&i.Highlight,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createPost = `-- name: CreatePost :one
+WITH tombstone AS (
+    UPDATE pruned_posts
+    SET last_seen_at = NOW()
+    WHERE pruned_posts.feed_id = $5
+    AND pruned_posts.post_key = COALESCE($6, $2)
+    RETURNING 1
+)
+INSERT INTO posts (created_at, updated_at, title, url, description, published_at, feed_id, guid, guid_is_permalink, author, content, safe_html, excerpt)
+SELECT
     NOW(),
     NOW(),
     $1,
     $2,
     $3,
     $4,
-    $5
-    )
-    ON CONFLICT (url) DO NOTHING
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
+    $10,
+    $11
+WHERE NOT EXISTS (SELECT 1 FROM tombstone)
+ON CONFLICT DO NOTHING
+RETURNING id
 `
 
 type CreatePostParams struct {
-	Title       string
-	Url         string
-	Description string
-	PublishedAt time.Time
-	FeedID      int32
+	Title           string
+	Url             string
+	Description     string
+	PublishedAt     time.Time
+	FeedID          int32
+	Guid            sql.NullString
+	GuidIsPermalink sql.NullBool
+	Author          sql.NullString
+	Content         sql.NullString
+	SafeHtml        sql.NullString
+	Excerpt         sql.NullString
 }
 
-func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) error {
-	_, err := q.db.ExecContext(ctx, createPost,
+func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, createPost,
 		arg.Title,
 		arg.Url,
 		arg.Description,
 		arg.PublishedAt,
 		arg.FeedID,
+		arg.Guid,
+		arg.GuidIsPermalink,
+		arg.Author,
+		arg.Content,
+		arg.SafeHtml,
+		arg.Excerpt,
 	)
-	return err
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const getPost = `-- name: GetPost :one
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, guid, guid_is_permalink, author, content, edited_at, safe_html, excerpt, full_content FROM posts
+WHERE id = $1
+`
+
+func (q *Queries) GetPost(ctx context.Context, id int32) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPost, id)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.Guid,
+		&i.GuidIsPermalink,
+		&i.Author,
+		&i.Content,
+		&i.EditedAt,
+		&i.SafeHtml,
+		&i.Excerpt,
+		&i.FullContent,
+	)
+	return i, err
 }
 
 const getPostsForUser = `-- name: GetPostsForUser :many
 SELECT
-    posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id
+    posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.guid, posts.guid_is_permalink, posts.author, posts.content, posts.edited_at, posts.safe_html, posts.excerpt, posts.full_content
 FROM users
 INNER JOIN feed_follows
     ON users.id = feed_follows.user_id
@@ -81,6 +311,14 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.Guid,
+			&i.GuidIsPermalink,
+			&i.Author,
+			&i.Content,
+			&i.EditedAt,
+			&i.SafeHtml,
+			&i.Excerpt,
+			&i.FullContent,
 		); err != nil {
 			return nil, err
 		}
@@ -94,3 +332,257 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 	}
 	return items, nil
 }
+
+const getPostsWithFeedForUser = `-- name: GetPostsWithFeedForUser :many
+SELECT
+    posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.guid, posts.guid_is_permalink, posts.author, posts.content, posts.edited_at, posts.safe_html, posts.excerpt, posts.full_content,
+    feeds.name AS feed_name,
+    feeds.url AS feed_url
+FROM users
+INNER JOIN feed_follows
+    ON users.id = feed_follows.user_id
+INNER JOIN feeds
+    ON feed_follows.feed_id = feeds.id
+INNER JOIN posts
+    ON feeds.id = posts.feed_id
+WHERE users.name = $1
+ORDER BY posts.published_at DESC
+LIMIT $2
+`
+
+type GetPostsWithFeedForUserParams struct {
+	Name  string
+	Limit int32
+}
+
+type GetPostsWithFeedForUserRow struct {
+	ID              int32
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Title           string
+	Url             string
+	Description     string
+	PublishedAt     time.Time
+	FeedID          int32
+	Guid            sql.NullString
+	GuidIsPermalink sql.NullBool
+	Author          sql.NullString
+	Content         sql.NullString
+	EditedAt        sql.NullTime
+	SafeHtml        sql.NullString
+	Excerpt         sql.NullString
+	FullContent     sql.NullString
+	FeedName        string
+	FeedUrl         string
+}
+
+func (q *Queries) GetPostsWithFeedForUser(ctx context.Context, arg GetPostsWithFeedForUserParams) ([]GetPostsWithFeedForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsWithFeedForUser, arg.Name, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsWithFeedForUserRow
+	for rows.Next() {
+		var i GetPostsWithFeedForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Guid,
+			&i.GuidIsPermalink,
+			&i.Author,
+			&i.Content,
+			&i.EditedAt,
+			&i.SafeHtml,
+			&i.Excerpt,
+			&i.FullContent,
+			&i.FeedName,
+			&i.FeedUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnreadPostsForUser = `-- name: GetUnreadPostsForUser :many
+SELECT
+    posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.guid, posts.guid_is_permalink, posts.author, posts.content, posts.edited_at, posts.safe_html, posts.excerpt, posts.full_content
+FROM users
+INNER JOIN feed_follows
+    ON users.id = feed_follows.user_id
+INNER JOIN feeds
+    ON feed_follows.feed_id = feeds.id
+INNER JOIN posts
+    ON feeds.id = posts.feed_id
+LEFT JOIN post_reads
+    ON posts.id = post_reads.post_id
+    AND users.id = post_reads.user_id
+WHERE users.name = $1
+AND post_reads.post_id IS NULL
+ORDER BY posts.published_at DESC
+LIMIT $2
+`
+
+type GetUnreadPostsForUserParams struct {
+	Name  string
+	Limit int32
+}
+
+func (q *Queries) GetUnreadPostsForUser(ctx context.Context, arg GetUnreadPostsForUserParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getUnreadPostsForUser, arg.Name, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Guid,
+			&i.GuidIsPermalink,
+			&i.Author,
+			&i.Content,
+			&i.EditedAt,
+			&i.SafeHtml,
+			&i.Excerpt,
+			&i.FullContent,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchPostsForUser = `-- name: SearchPostsForUser :many
+SELECT
+    posts.id,
+    posts.title,
+    posts.url,
+    posts.published_at,
+    feeds.name AS feed_name,
+    ts_rank(
+        setweight(to_tsvector('english', posts.title), 'A') || setweight(to_tsvector('english', posts.description), 'B'),
+        search_query
+    )::real AS rank,
+    ts_headline(
+        'english',
+        regexp_replace(posts.description, '<[^>]*>', ' ', 'g'),
+        search_query,
+        'StartSel=*, StopSel=*, MaxFragments=2, MaxWords=30, MinWords=10'
+    )::text AS snippet
+FROM users
+INNER JOIN feed_follows
+    ON users.id = feed_follows.user_id
+INNER JOIN feeds
+    ON feed_follows.feed_id = feeds.id
+INNER JOIN posts
+    ON feeds.id = posts.feed_id
+CROSS JOIN websearch_to_tsquery('english', $1) AS search_query
+WHERE users.name = $2
+AND (setweight(to_tsvector('english', posts.title), 'A') || setweight(to_tsvector('english', posts.description), 'B')) @@ search_query
+AND ($3::text IS NULL OR feeds.url = $3 OR feeds.name = $3)
+AND ($4::timestamp IS NULL OR posts.published_at >= $4)
+ORDER BY rank DESC, posts.published_at DESC
+LIMIT $5
+`
+
+type SearchPostsForUserParams struct {
+	Query      string
+	Name       string
+	Feed       sql.NullString
+	Since      sql.NullTime
+	MaxResults int32
+}
+
+type SearchPostsForUserRow struct {
+	ID          int32
+	Title       string
+	Url         string
+	PublishedAt time.Time
+	FeedName    string
+	Rank        float32
+	Snippet     string
+}
+
+func (q *Queries) SearchPostsForUser(ctx context.Context, arg SearchPostsForUserParams) ([]SearchPostsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchPostsForUser,
+		arg.Query,
+		arg.Name,
+		arg.Feed,
+		arg.Since,
+		arg.MaxResults,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchPostsForUserRow
+	for rows.Next() {
+		var i SearchPostsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.PublishedAt,
+			&i.FeedName,
+			&i.Rank,
+			&i.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setPostFullContent = `-- name: SetPostFullContent :exec
+UPDATE posts
+SET full_content = $2,
+updated_at = NOW()
+WHERE id = $1
+`
+
+type SetPostFullContentParams struct {
+	ID          int32
+	FullContent sql.NullString
+}
+
+func (q *Queries) SetPostFullContent(ctx context.Context, arg SetPostFullContentParams) error {
+	_, err := q.db.ExecContext(ctx, setPostFullContent, arg.ID, arg.FullContent)
+	return err
+}
@@ -0,0 +1,95 @@
+package main
+
+import "strconv"
+
+type JSONFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	Description string         `json:"description"`
+	Items       []JSONFeedItem `json:"items"`
+}
+
+type JSONFeedItem struct {
+	ID            any                  `json:"id"`
+	URL           string               `json:"url"`
+	ExternalURL   string               `json:"external_url"`
+	Title         string               `json:"title"`
+	Summary       string               `json:"summary"`
+	ContentHTML   string               `json:"content_html"`
+	ContentText   string               `json:"content_text"`
+	DatePublished string               `json:"date_published"`
+	DateModified  string               `json:"date_modified"`
+	Author        *JSONFeedAuthor      `json:"author"`
+	Authors       []JSONFeedAuthor     `json:"authors"`
+	Tags          []string             `json:"tags"`
+	Attachments   []JSONFeedAttachment `json:"attachments"`
+}
+
+type JSONFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+type JSONFeedAttachment struct {
+	URL         string `json:"url"`
+	MimeType    string `json:"mime_type"`
+	SizeInBytes int64  `json:"size_in_bytes"`
+}
+
+func (j *JSONFeed) toRSS() *RSSFeed {
+	var feed RSSFeed
+	feed.Channel.Title = j.Title
+	feed.Channel.Link = j.HomePageURL
+	feed.Channel.Description = j.Description
+
+	for _, entry := range j.Items {
+		item := RSSItem{
+			Title:       entry.Title,
+			Link:        entry.URL,
+			Description: entry.ContentHTML,
+			PubDate:     entry.DatePublished,
+			GUID:        RSSGUID{Value: jsonFeedID(entry.ID), IsPermaLink: "false"},
+			Content:     entry.ContentHTML,
+			Categories:  entry.Tags,
+		}
+		if item.Link == "" {
+			item.Link = entry.ExternalURL
+		}
+		if item.Description == "" {
+			item.Description = entry.ContentText
+		}
+		if item.Description == "" {
+			item.Description = entry.Summary
+		}
+		if item.PubDate == "" {
+			item.PubDate = entry.DateModified
+		}
+		if len(entry.Authors) > 0 {
+			item.Author = entry.Authors[0].Name
+		} else if entry.Author != nil {
+			item.Author = entry.Author.Name
+		}
+		for _, attachment := range entry.Attachments {
+			enclosure := RSSEnclosure{URL: attachment.URL, Type: attachment.MimeType}
+			if attachment.SizeInBytes > 0 {
+				enclosure.Length = strconv.FormatInt(attachment.SizeInBytes, 10)
+			}
+			item.Enclosures = append(item.Enclosures, enclosure)
+		}
+		feed.Channel.Item = append(feed.Channel.Item, item)
+	}
+
+	return &feed
+}
+
+// JSON Feed 1.0 allowed numeric ids, 1.1 requires strings
+func jsonFeedID(id any) string {
+	switch v := id.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
@@ -38,18 +38,41 @@ func main() {
 	cmdMap := make(map[string]func(*state, command) error)
 	cmds := commands{
 		commandMap: cmdMap,
+		listing:    make(map[string]bool),
 	}
 	cmds.register("login", handlerLogin)
 	cmds.register("register", handlerRegister)
 	cmds.register("reset", handlerReset)
-	cmds.register("users", handlerUsers)
+	cmds.registerListing("users", handlerUsers)
 	cmds.register("agg", handlerAgg)
 	cmds.register("addfeed", middlewareLoggedIn(handlerAddFeed))
-	cmds.register("feeds", handlerFeeds)
+	cmds.registerListing("feeds", handlerFeeds)
+	cmds.registerListing("feedstatus", handlerFeedStatus)
+	cmds.register("retention", handlerRetention)
+	cmds.register("prune", handlerPrune)
 	cmds.register("follow", middlewareLoggedIn(handlerFollow))
-	cmds.register("following", middlewareLoggedIn(handlerFollowing))
+	cmds.registerListing("following", middlewareLoggedIn(handlerFollowing))
 	cmds.register("unfollow", middlewareLoggedIn(handlerUnfollow))
-	cmds.register("browse", handlerBrowse)
+	cmds.registerListing("browse", handlerBrowse)
+	cmds.register("tui", middlewareLoggedIn(handlerTUI))
+	cmds.register("read", middlewareLoggedIn(handlerRead))
+	cmds.register("unread", middlewareLoggedIn(handlerUnread))
+	cmds.register("markall", middlewareLoggedIn(handlerMarkAll))
+	cmds.register("save", middlewareLoggedIn(handlerSave))
+	cmds.register("unsave", middlewareLoggedIn(handlerUnsave))
+	cmds.registerListing("saved", middlewareLoggedIn(handlerSaved))
+	cmds.registerListing("search", middlewareLoggedIn(handlerSearch))
+	cmds.register("history", handlerHistory)
+	cmds.registerListing("rule", middlewareLoggedIn(handlerRule))
+	cmds.register("import-opml", middlewareLoggedIn(handlerImportOPML))
+	cmds.register("export-opml", middlewareLoggedIn(handlerExportOPML))
+	cmds.register("export-feed", middlewareLoggedIn(handlerExportFeed))
+	cmds.register("podcast", handlerPodcast)
+	cmds.register("fullcontent", middlewareLoggedIn(handlerFullContent))
+	cmds.registerListing("downloads", middlewareLoggedIn(handlerDownloads))
+	cmds.register("download", middlewareLoggedIn(handlerDownload))
+	cmds.register("apikey", middlewareLoggedIn(handlerAPIKey))
+	cmds.register("serve", handlerServe)
 
 	args := os.Args
 	if len(args) < 2 {
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/xml"
+	"strings"
+	"time"
+
+	"github.com/scottyloveless/gator/internal/database"
+)
+
+type OPML struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    struct {
+		Title       string `xml:"title"`
+		DateCreated string `xml:"dateCreated,omitempty"`
+	} `xml:"head"`
+	Body struct {
+		Outlines []OPMLOutline `xml:"outline"`
+	} `xml:"body"`
+}
+
+type OPMLOutline struct {
+	Text     string        `xml:"text,attr"`
+	Title    string        `xml:"title,attr,omitempty"`
+	Type     string        `xml:"type,attr,omitempty"`
+	XMLURL   string        `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string        `xml:"htmlUrl,attr,omitempty"`
+	Outlines []OPMLOutline `xml:"outline"`
+}
+
+type opmlSubscription struct {
+	Name    string
+	URL     string
+	SiteURL string
+	Folder  string
+}
+
+func (o OPMLOutline) name() string {
+	if o.Title != "" {
+		return o.Title
+	}
+	return o.Text
+}
+
+// subscriptions flattens nested category outlines into folder paths like "Tech/Go"
+func (o *OPML) subscriptions() []opmlSubscription {
+	var subs []opmlSubscription
+	var walk func(outlines []OPMLOutline, folders []string)
+	walk = func(outlines []OPMLOutline, folders []string) {
+		for _, outline := range outlines {
+			if outline.XMLURL != "" {
+				subs = append(subs, opmlSubscription{
+					Name:    strings.TrimSpace(outline.name()),
+					URL:     strings.TrimSpace(outline.XMLURL),
+					SiteURL: strings.TrimSpace(outline.HTMLURL),
+					Folder:  strings.Join(folders, "/"),
+				})
+				continue
+			}
+			walk(outline.Outlines, append(folders, strings.TrimSpace(outline.name())))
+		}
+	}
+	walk(o.Body.Outlines, nil)
+	return subs
+}
+
+func buildOPML(title string, follows []database.GetFeedFollowsForUserRow) OPML {
+	opml := OPML{Version: "2.0"}
+	opml.Head.Title = title
+	opml.Head.DateCreated = time.Now().Format(time.RFC1123Z)
+
+	root := &OPMLOutline{}
+	for _, follow := range follows {
+		parent := root
+		if follow.Folder.Valid {
+			for _, folder := range strings.Split(follow.Folder.String, "/") {
+				parent = parent.child(folder)
+			}
+		}
+		parent.Outlines = append(parent.Outlines, OPMLOutline{
+			Text:    follow.FeedName,
+			Title:   follow.FeedName,
+			Type:    "rss",
+			XMLURL:  follow.FeedUrl,
+			HTMLURL: follow.FeedSiteUrl.String,
+		})
+	}
+	opml.Body.Outlines = root.Outlines
+
+	return opml
+}
+
+func (o *OPMLOutline) child(text string) *OPMLOutline {
+	for i := range o.Outlines {
+		if o.Outlines[i].XMLURL == "" && o.Outlines[i].Text == text {
+			return &o.Outlines[i]
+		}
+	}
+	o.Outlines = append(o.Outlines, OPMLOutline{Text: text, Title: text})
+	return &o.Outlines[len(o.Outlines)-1]
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	outputJSON  = "json"
+	outputCSV   = "csv"
+	outputTable = "table"
+)
+
+// maxTableCell keeps long descriptions from blowing up table output.
+const maxTableCell = 60
+
+// extractOutputFlag removes --output from a command's arguments and returns
+// the chosen format. Arguments after "--" are left alone.
+func extractOutputFlag(args []string) ([]string, string, error) {
+	var rest []string
+	format := ""
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "output" {
+			rest = append(rest, arg)
+			continue
+		}
+		if !hasValue {
+			if i+1 == len(args) {
+				return nil, "", fmt.Errorf("--output needs a format: json, csv or table")
+			}
+			i++
+			value = args[i]
+		}
+		format = value
+	}
+
+	switch format {
+	case "", outputJSON, outputCSV, outputTable:
+		return rest, format, nil
+	default:
+		return nil, "", fmt.Errorf("unknown output format %q, use json, csv or table", format)
+	}
+}
+
+// render prints results, a slice of structs with json tags, in the format
+// chosen with --output. Without one, printText prints the usual text.
+func render(format string, results any, printText func() error) error {
+	switch format {
+	case outputJSON:
+		v := reflect.ValueOf(results)
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			results = reflect.MakeSlice(v.Type(), 0, 0).Interface()
+		}
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	case outputCSV:
+		w := csv.NewWriter(os.Stdout)
+		columns, rows := tabulate(results)
+		w.Write(columns)
+		w.WriteAll(rows)
+		return w.Error()
+	case outputTable:
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		columns, rows := tabulate(results)
+		fmt.Fprintln(w, strings.ToUpper(strings.Join(columns, "\t")))
+		for _, row := range rows {
+			for i, cell := range row {
+				row[i] = truncateCell(cell)
+			}
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		return w.Flush()
+	default:
+		return printText()
+	}
+}
+
+// tabulate flattens a slice of structs into rows, using the json field names
+// as column headers.
+func tabulate(results any) ([]string, [][]string) {
+	v := reflect.ValueOf(results)
+	t := v.Type().Elem()
+
+	var columns []string
+	var fields []int
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		columns = append(columns, name)
+		fields = append(fields, i)
+	}
+
+	rows := make([][]string, v.Len())
+	for i := range rows {
+		for _, field := range fields {
+			rows[i] = append(rows[i], formatCell(v.Index(i).Field(field)))
+		}
+	}
+	return columns, rows
+}
+
+func formatCell(v reflect.Value) string {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	switch value := v.Interface().(type) {
+	case time.Time:
+		return value.Format(time.RFC3339)
+	case []string:
+		return strings.Join(value, ", ")
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+func truncateCell(cell string) string {
+	cell = strings.Join(strings.Fields(cell), " ")
+	if utf8.RuneCountInString(cell) <= maxTableCell {
+		return cell
+	}
+	return string([]rune(cell)[:maxTableCell-1]) + "…"
+}
@@ -1,67 +1,191 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"html"
 	"io"
 	"net/http"
+	"strings"
 )
 
 type RSSFeed struct {
 	Channel struct {
-		Title       string    `xml:"title"`
-		Link        string    `xml:"link"`
-		Description string    `xml:"description"`
-		Item        []RSSItem `xml:"item"`
+		Title         string    `xml:"title"`
+		Link          string    `xml:"link"`
+		Description   string    `xml:"description"`
+		LastBuildDate string    `xml:"lastBuildDate"`
+		PubDate       string    `xml:"pubDate"`
+		Item          []RSSItem `xml:"item"`
 	} `xml:"channel"`
 }
 
 type RSSItem struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
-	Description string `xml:"description"`
-	PubDate     string `xml:"pubDate"`
+	Title       string         `xml:"title"`
+	Link        string         `xml:"link"`
+	Description string         `xml:"description"`
+	PubDate     string         `xml:"pubDate"`
+	GUID        RSSGUID        `xml:"guid"`
+	Author      string         `xml:"author"`
+	Creator     string         `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Categories  []string       `xml:"category"`
+	Content     string         `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Enclosures  []RSSEnclosure `xml:"enclosure"`
+}
+
+type RSSGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink string `xml:"isPermaLink,attr"`
+}
+
+type RSSEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+// guids are permalinks unless the feed says otherwise
+func (g RSSGUID) permaLink() bool {
+	return !strings.EqualFold(strings.TrimSpace(g.IsPermaLink), "false")
+}
+
+func (i RSSItem) author() string {
+	if i.Creator != "" {
+		return strings.TrimSpace(i.Creator)
+	}
+	return strings.TrimSpace(i.Author)
+}
+
+var errFeedNotModified = errors.New("feed not modified")
+
+var feedClient = &http.Client{}
+
+type feedValidators struct {
+	ETag         string
+	LastModified string
 }
 
 func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
-	var feed *RSSFeed
+	feed, _, err := fetchFeedConditional(ctx, feedURL, feedValidators{})
+	return feed, err
+}
 
+// fetchFeedConditional sends the validators from the previous fetch and returns
+// errFeedNotModified when the server answers 304
+func fetchFeedConditional(ctx context.Context, feedURL string, cached feedValidators) (*RSSFeed, feedValidators, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
 	if err != nil {
-		return nil, err
+		return nil, cached, err
 	}
 
 	req.Header.Set("User-Agent", "gator")
+	if cached.ETag != "" {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+	if cached.LastModified != "" {
+		req.Header.Set("If-Modified-Since", cached.LastModified)
+	}
 
-	client := &http.Client{}
-
-	res, err := client.Do(req)
+	res, err := feedClient.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, cached, err
 	}
 	defer res.Body.Close()
 
+	validators := feedValidators{
+		ETag:         res.Header.Get("ETag"),
+		LastModified: res.Header.Get("Last-Modified"),
+	}
+
+	if res.StatusCode == http.StatusNotModified {
+		if validators.ETag == "" {
+			validators.ETag = cached.ETag
+		}
+		if validators.LastModified == "" {
+			validators.LastModified = cached.LastModified
+		}
+		return nil, validators, errFeedNotModified
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, cached, fmt.Errorf("unexpected status: %s", res.Status)
+	}
+
 	data, err := io.ReadAll(res.Body)
 	if err != nil {
-		return nil, err
+		return nil, cached, err
 	}
 
-	if err := xml.Unmarshal(data, &feed); err != nil {
-		return nil, err
+	feed, err := parseFeed(data, res.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, cached, err
 	}
 
 	feed.Channel.Title = html.UnescapeString(feed.Channel.Title)
 	feed.Channel.Description = html.UnescapeString(feed.Channel.Description)
 
 	if len(feed.Channel.Item) == 0 {
-		return nil, fmt.Errorf("RSS Feed has no items")
+		return nil, cached, fmt.Errorf("RSS Feed has no items")
 	}
 	for i := range feed.Channel.Item {
 		feed.Channel.Item[i].Title = html.UnescapeString(feed.Channel.Item[i].Title)
 		feed.Channel.Item[i].Description = html.UnescapeString(feed.Channel.Item[i].Description)
 	}
 
-	return feed, nil
+	return feed, validators, nil
+}
+
+func parseFeed(data []byte, contentType string) (*RSSFeed, error) {
+	if isJSONFeed(data, contentType) {
+		var jsonFeed JSONFeed
+		if err := json.Unmarshal(bytes.TrimPrefix(data, utf8BOM), &jsonFeed); err != nil {
+			return nil, err
+		}
+		return jsonFeed.toRSS(), nil
+	}
+
+	root, err := xmlRootName(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if root.Local == "feed" && root.Space == atomNamespace {
+		var atom AtomFeed
+		if err := xml.Unmarshal(data, &atom); err != nil {
+			return nil, err
+		}
+		return atom.toRSS(), nil
+	}
+
+	var feed RSSFeed
+	if err := xml.Unmarshal(data, &feed); err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+func isJSONFeed(data []byte, contentType string) bool {
+	if strings.Contains(strings.ToLower(contentType), "json") {
+		return true
+	}
+	trimmed := bytes.TrimLeft(bytes.TrimPrefix(data, utf8BOM), " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '{'
+}
+
+func xmlRootName(data []byte) (xml.Name, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			return xml.Name{}, fmt.Errorf("couldn't find root element: %w", err)
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name, nil
+		}
+	}
 }
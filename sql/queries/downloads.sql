@@ -0,0 +1,49 @@
+-- name: GetEnclosuresForUser :many
+SELECT
+    post_enclosures.id,
+    post_enclosures.post_id,
+    post_enclosures.url,
+    post_enclosures.type,
+    post_enclosures.length,
+    posts.title AS post_title,
+    posts.published_at,
+    feeds.name AS feed_name,
+    downloads.status,
+    downloads.bytes_downloaded,
+    downloads.total_bytes,
+    downloads.path
+FROM feed_follows
+INNER JOIN feeds
+    ON feed_follows.feed_id = feeds.id
+INNER JOIN posts
+    ON feeds.id = posts.feed_id
+INNER JOIN post_enclosures
+    ON posts.id = post_enclosures.post_id
+LEFT JOIN downloads
+    ON post_enclosures.id = downloads.enclosure_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+AND (
+    (sqlc.narg(post_id)::int IS NULL AND feeds.is_podcast)
+    OR posts.id = sqlc.narg(post_id)
+)
+ORDER BY posts.published_at DESC;
+
+-- name: UpsertDownload :exec
+INSERT INTO downloads (created_at, updated_at, enclosure_id, path, status, bytes_downloaded, total_bytes, last_error)
+VALUES (
+    NOW(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
+    )
+    ON CONFLICT (enclosure_id) DO UPDATE
+    SET updated_at = NOW(),
+    path = EXCLUDED.path,
+    status = EXCLUDED.status,
+    bytes_downloaded = EXCLUDED.bytes_downloaded,
+    total_bytes = EXCLUDED.total_bytes,
+    last_error = EXCLUDED.last_error;
@@ -24,17 +24,27 @@ INNER JOIN feeds
 SELECT
     feed_follows.user_id,
     feed_follows.feed_id,
+    feed_follows.folder,
     users.name,
     feeds.url AS feed_url,
-    feeds.name AS feed_name
+    feeds.name AS feed_name,
+    feeds.site_url AS feed_site_url
 FROM feed_follows
 INNER JOIN users
     ON feed_follows.user_id = users.id
 INNER JOIN feeds
     ON feed_follows.feed_id = feeds.id
-WHERE feed_follows.user_id = $1;
+WHERE feed_follows.user_id = $1
+ORDER BY feed_follows.folder NULLS FIRST, feeds.name;
 
 -- name: Unfollow :exec
 DELETE FROM feed_follows
 WHERE user_id = (SELECT id FROM users WHERE users.name = $1)
 AND feed_id = (SELECT id FROM feeds WHERE feeds.url = $2);
+
+-- name: SetFeedFollowFolder :exec
+UPDATE feed_follows
+SET folder = $3,
+updated_at = NOW()
+WHERE user_id = $1
+AND feed_id = $2;
@@ -23,12 +23,55 @@ WHERE url = $1;
 -- name: MarkFeedFetched :one
 UPDATE feeds
 SET last_fetched_at = NOW(),
-updated_at = NOW()
+updated_at = NOW(),
+etag = $2,
+last_modified = $3,
+last_success_at = NOW(),
+consecutive_failures = 0,
+last_error = NULL,
+next_fetch_at = NULL
 WHERE id = $1
 RETURNING *;
 
--- name: GetNextFeedToFetch :one
-SELECT * FROM feeds
-ORDER BY last_fetched_at ASC NULLS FIRST
-LIMIT 1;
+-- name: MarkFeedFailed :exec
+UPDATE feeds
+SET updated_at = NOW(),
+consecutive_failures = consecutive_failures + 1,
+last_error = $2,
+next_fetch_at = NOW() + LEAST(INTERVAL '1 minute' * POWER(2, LEAST(consecutive_failures, 11)), INTERVAL '1 day')
+WHERE id = $1;
+
+-- name: ClaimFeedsToFetch :many
+UPDATE feeds
+SET last_fetched_at = NOW(),
+updated_at = NOW()
+WHERE id IN (
+    SELECT id FROM feeds
+    WHERE (next_fetch_at IS NULL OR next_fetch_at <= NOW())
+    AND (
+        last_fetched_at IS NULL
+        OR last_fetched_at < NOW() - make_interval(secs => sqlc.arg(stale_seconds)::float8)
+    )
+    ORDER BY last_fetched_at ASC NULLS FIRST
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: SetFeedSiteURL :exec
+UPDATE feeds
+SET site_url = $2,
+updated_at = NOW()
+WHERE id = $1;
+
+-- name: SetFeedPodcast :execrows
+UPDATE feeds
+SET is_podcast = $2,
+updated_at = NOW()
+WHERE url = $1;
 
+-- name: SetFeedFullContent :exec
+UPDATE feeds
+SET fetch_full_content = $2,
+updated_at = NOW()
+WHERE id = $1;
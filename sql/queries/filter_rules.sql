@@ -0,0 +1,26 @@
+-- name: CreateFilterRule :one
+INSERT INTO filter_rules (created_at, user_id, action, field, pattern, feed_id)
+VALUES (
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+    )
+RETURNING *;
+
+-- name: GetFilterRulesForUser :many
+SELECT
+    filter_rules.*,
+    feeds.name AS feed_name
+FROM filter_rules
+LEFT JOIN feeds
+    ON filter_rules.feed_id = feeds.id
+WHERE filter_rules.user_id = $1
+ORDER BY filter_rules.id;
+
+-- name: DeleteFilterRule :execrows
+DELETE FROM filter_rules
+WHERE id = $1
+AND user_id = $2;
@@ -0,0 +1,33 @@
+-- name: QueueFullContent :exec
+INSERT INTO full_content_queue (post_id, queued_at, next_attempt_at)
+VALUES (
+    $1,
+    NOW(),
+    NOW()
+    )
+ON CONFLICT DO NOTHING;
+
+-- name: ClaimFullContentJobs :many
+UPDATE full_content_queue
+SET attempts = attempts + 1,
+next_attempt_at = NOW() + LEAST(INTERVAL '1 minute' * POWER(2, LEAST(attempts, 11)), INTERVAL '1 day')
+FROM posts
+WHERE full_content_queue.post_id = posts.id
+AND full_content_queue.post_id IN (
+    SELECT post_id FROM full_content_queue
+    WHERE next_attempt_at <= NOW()
+    AND attempts < sqlc.arg(max_attempts)
+    ORDER BY queued_at
+    LIMIT sqlc.arg(row_limit)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING posts.id, posts.url;
+
+-- name: FinishFullContentJob :exec
+DELETE FROM full_content_queue
+WHERE post_id = $1;
+
+-- name: FailFullContentJob :exec
+UPDATE full_content_queue
+SET last_error = $2
+WHERE post_id = $1;
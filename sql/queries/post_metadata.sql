@@ -0,0 +1,17 @@
+-- name: CreatePostCategory :exec
+INSERT INTO post_categories (post_id, name)
+VALUES (
+    $1,
+    $2
+    )
+    ON CONFLICT (post_id, name) DO NOTHING;
+
+-- name: CreatePostEnclosure :exec
+INSERT INTO post_enclosures (post_id, url, type, length)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4
+    )
+    ON CONFLICT (post_id, url) DO NOTHING;
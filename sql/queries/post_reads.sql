@@ -0,0 +1,71 @@
+-- name: MarkPostRead :exec
+INSERT INTO post_reads (user_id, post_id, read_at)
+VALUES (
+    $1,
+    $2,
+    NOW()
+    )
+    ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: MarkPostUnread :exec
+DELETE FROM post_reads
+WHERE user_id = $1
+AND post_id = $2;
+
+-- name: MarkAllPostsRead :execrows
+INSERT INTO post_reads (user_id, post_id, read_at)
+SELECT feed_follows.user_id, posts.id, NOW()
+FROM feed_follows
+INNER JOIN feeds
+    ON feed_follows.feed_id = feeds.id
+INNER JOIN posts
+    ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+AND (sqlc.narg(feed_url)::text IS NULL OR feeds.url = sqlc.narg(feed_url))
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: MarkAllPostsUnread :execrows
+DELETE FROM post_reads
+WHERE user_id = sqlc.arg(user_id)
+AND (sqlc.narg(feed_url)::text IS NULL OR post_id IN (
+    SELECT posts.id
+    FROM posts
+    INNER JOIN feeds
+        ON posts.feed_id = feeds.id
+    WHERE feeds.url = sqlc.narg(feed_url)
+));
+
+-- name: GetPostsWithReadStateForUser :many
+SELECT
+    posts.*,
+    feeds.name AS feed_name,
+    EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = posts.id
+        AND post_reads.user_id = sqlc.arg(user_id)
+    ) AS is_read,
+    (
+        SELECT filter_rules.pattern FROM filter_rules
+        WHERE filter_rules.user_id = sqlc.arg(user_id)
+        AND filter_rules.action = 'highlight'
+        AND (filter_rules.feed_id IS NULL OR filter_rules.feed_id = posts.feed_id)
+        AND filter_rule_matches(filter_rules.field, filter_rules.pattern, posts.title, posts.description)
+        ORDER BY filter_rules.id
+        LIMIT 1
+    ) AS highlight
+FROM posts
+INNER JOIN feeds
+    ON posts.feed_id = feeds.id
+INNER JOIN feed_follows
+    ON feeds.id = feed_follows.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+AND (sqlc.narg(feed_id)::int IS NULL OR posts.feed_id = sqlc.narg(feed_id))
+AND (sqlc.arg(show_muted)::bool OR NOT EXISTS (
+    SELECT 1 FROM filter_rules
+    WHERE filter_rules.user_id = sqlc.arg(user_id)
+    AND filter_rules.action = 'mute'
+    AND (filter_rules.feed_id IS NULL OR filter_rules.feed_id = posts.feed_id)
+    AND filter_rule_matches(filter_rules.field, filter_rules.pattern, posts.title, posts.description)
+))
+ORDER BY posts.published_at DESC
+LIMIT sqlc.arg(row_limit);
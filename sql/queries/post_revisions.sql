@@ -0,0 +1,34 @@
+-- name: UpdatePostIfChanged :execrows
+WITH existing AS (
+    SELECT id, title, description, content
+    FROM posts
+    WHERE (
+        (sqlc.narg(guid)::text IS NOT NULL AND posts.feed_id = sqlc.arg(feed_id) AND posts.guid = sqlc.narg(guid))
+        OR (sqlc.narg(guid)::text IS NULL AND posts.feed_id = sqlc.arg(feed_id) AND posts.guid IS NULL AND posts.url = sqlc.arg(url))
+    )
+    AND (
+        posts.title IS DISTINCT FROM sqlc.arg(title)
+        OR posts.description IS DISTINCT FROM sqlc.arg(description)
+        OR posts.content IS DISTINCT FROM sqlc.narg(content)
+    )
+    FOR UPDATE
+), revision AS (
+    INSERT INTO post_revisions (created_at, post_id, title, description, content)
+    SELECT NOW(), existing.id, existing.title, existing.description, existing.content
+    FROM existing
+)
+UPDATE posts
+SET title = sqlc.arg(title),
+description = sqlc.arg(description),
+content = sqlc.narg(content),
+safe_html = sqlc.narg(safe_html),
+excerpt = sqlc.narg(excerpt),
+updated_at = NOW(),
+edited_at = NOW()
+FROM existing
+WHERE posts.id = existing.id;
+
+-- name: GetPostRevisions :many
+SELECT * FROM post_revisions
+WHERE post_id = $1
+ORDER BY created_at ASC, id ASC;
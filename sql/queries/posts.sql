@@ -1,15 +1,29 @@
--- name: CreatePost :exec
-INSERT INTO posts (created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES (
+-- name: CreatePost :one
+WITH tombstone AS (
+    UPDATE pruned_posts
+    SET last_seen_at = NOW()
+    WHERE pruned_posts.feed_id = $5
+    AND pruned_posts.post_key = COALESCE($6, $2)
+    RETURNING 1
+)
+INSERT INTO posts (created_at, updated_at, title, url, description, published_at, feed_id, guid, guid_is_permalink, author, content, safe_html, excerpt)
+SELECT
     NOW(),
     NOW(),
     $1,
     $2,
     $3,
     $4,
-    $5
-    )
-    ON CONFLICT (url) DO NOTHING;
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
+    $10,
+    $11
+WHERE NOT EXISTS (SELECT 1 FROM tombstone)
+ON CONFLICT DO NOTHING
+RETURNING id;
 
 -- name: GetPostsForUser :many
 SELECT
@@ -24,3 +38,142 @@ INNER JOIN posts
 WHERE users.name = $1
 ORDER BY posts.published_at DESC
 LIMIT $2;
+
+-- name: GetUnreadPostsForUser :many
+SELECT
+    posts.*
+FROM users
+INNER JOIN feed_follows
+    ON users.id = feed_follows.user_id
+INNER JOIN feeds
+    ON feed_follows.feed_id = feeds.id
+INNER JOIN posts
+    ON feeds.id = posts.feed_id
+LEFT JOIN post_reads
+    ON posts.id = post_reads.post_id
+    AND users.id = post_reads.user_id
+WHERE users.name = $1
+AND post_reads.post_id IS NULL
+ORDER BY posts.published_at DESC
+LIMIT $2;
+
+-- name: SearchPostsForUser :many
+SELECT
+    posts.id,
+    posts.title,
+    posts.url,
+    posts.published_at,
+    feeds.name AS feed_name,
+    ts_rank(
+        setweight(to_tsvector('english', posts.title), 'A') || setweight(to_tsvector('english', posts.description), 'B'),
+        search_query
+    )::real AS rank,
+    ts_headline(
+        'english',
+        regexp_replace(posts.description, '<[^>]*>', ' ', 'g'),
+        search_query,
+        'StartSel=*, StopSel=*, MaxFragments=2, MaxWords=30, MinWords=10'
+    )::text AS snippet
+FROM users
+INNER JOIN feed_follows
+    ON users.id = feed_follows.user_id
+INNER JOIN feeds
+    ON feed_follows.feed_id = feeds.id
+INNER JOIN posts
+    ON feeds.id = posts.feed_id
+CROSS JOIN websearch_to_tsquery('english', sqlc.arg(query)) AS search_query
+WHERE users.name = sqlc.arg(name)
+AND (setweight(to_tsvector('english', posts.title), 'A') || setweight(to_tsvector('english', posts.description), 'B')) @@ search_query
+AND (sqlc.narg(feed)::text IS NULL OR feeds.url = sqlc.narg(feed) OR feeds.name = sqlc.narg(feed))
+AND (sqlc.narg(since)::timestamp IS NULL OR posts.published_at >= sqlc.narg(since))
+ORDER BY rank DESC, posts.published_at DESC
+LIMIT sqlc.arg(max_results);
+
+-- name: GetPostsWithFeedForUser :many
+SELECT
+    posts.*,
+    feeds.name AS feed_name,
+    feeds.url AS feed_url
+FROM users
+INNER JOIN feed_follows
+    ON users.id = feed_follows.user_id
+INNER JOIN feeds
+    ON feed_follows.feed_id = feeds.id
+INNER JOIN posts
+    ON feeds.id = posts.feed_id
+WHERE users.name = $1
+ORDER BY posts.published_at DESC
+LIMIT $2;
+
+-- name: GetPost :one
+SELECT * FROM posts
+WHERE id = $1;
+
+-- name: BrowsePostsForUser :many
+SELECT
+    posts.*,
+    (
+        SELECT filter_rules.pattern FROM filter_rules
+        WHERE filter_rules.user_id = users.id
+        AND filter_rules.action = 'highlight'
+        AND (filter_rules.feed_id IS NULL OR filter_rules.feed_id = posts.feed_id)
+        AND filter_rule_matches(filter_rules.field, filter_rules.pattern, posts.title, posts.description)
+        ORDER BY filter_rules.id
+        LIMIT 1
+    ) AS highlight
+FROM users
+INNER JOIN feed_follows
+    ON users.id = feed_follows.user_id
+INNER JOIN feeds
+    ON feed_follows.feed_id = feeds.id
+INNER JOIN posts
+    ON feeds.id = posts.feed_id
+WHERE users.name = sqlc.arg(name)
+AND (NOT sqlc.arg(unread_only)::bool OR NOT EXISTS (
+    SELECT 1 FROM post_reads
+    WHERE post_reads.post_id = posts.id
+    AND post_reads.user_id = users.id
+))
+AND (sqlc.narg(feed)::text IS NULL OR feeds.url = sqlc.narg(feed) OR feeds.name = sqlc.narg(feed))
+AND (sqlc.narg(since)::timestamp IS NULL OR posts.published_at >= sqlc.narg(since))
+AND (sqlc.narg(before)::timestamp IS NULL OR posts.published_at < sqlc.narg(before))
+AND (sqlc.narg(title)::text IS NULL OR strpos(lower(posts.title), lower(sqlc.narg(title))) > 0)
+AND (sqlc.narg(after_id)::int IS NULL OR CASE sqlc.arg(sort)::text
+    WHEN 'oldest' THEN (posts.published_at, posts.id) > (SELECT published_at, id FROM posts WHERE id = sqlc.narg(after_id))
+    WHEN 'title' THEN (posts.title, posts.id) > (SELECT title, id FROM posts WHERE id = sqlc.narg(after_id))
+    ELSE (posts.published_at, posts.id) < (SELECT published_at, id FROM posts WHERE id = sqlc.narg(after_id))
+END)
+AND (sqlc.arg(show_muted)::bool OR NOT EXISTS (
+    SELECT 1 FROM filter_rules
+    WHERE filter_rules.user_id = users.id
+    AND filter_rules.action = 'mute'
+    AND (filter_rules.feed_id IS NULL OR filter_rules.feed_id = posts.feed_id)
+    AND filter_rule_matches(filter_rules.field, filter_rules.pattern, posts.title, posts.description)
+))
+ORDER BY
+    CASE WHEN sqlc.arg(sort) = 'oldest' THEN posts.published_at END ASC,
+    CASE WHEN sqlc.arg(sort) = 'title' THEN posts.title END ASC,
+    CASE WHEN sqlc.arg(sort) IN ('oldest', 'title') THEN posts.id END ASC,
+    posts.published_at DESC,
+    posts.id DESC
+LIMIT sqlc.arg(row_limit)
+OFFSET sqlc.arg(row_offset);
+
+-- name: SetPostFullContent :exec
+UPDATE posts
+SET full_content = $2,
+updated_at = NOW()
+WHERE id = $1;
+
+-- name: AdoptPostGUID :execrows
+UPDATE posts
+SET guid = $3,
+guid_is_permalink = $4
+WHERE feed_id = $1
+AND url = $2
+AND guid IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM posts existing
+    WHERE existing.feed_id = $1
+    AND existing.guid = $3
+);
@@ -0,0 +1,80 @@
+-- name: PrunePosts :one
+WITH ranked AS (
+    SELECT
+        posts.id,
+        posts.published_at,
+        ROW_NUMBER() OVER (PARTITION BY posts.feed_id ORDER BY posts.published_at DESC, posts.id DESC) AS position,
+        COALESCE(feeds.retention_days, sqlc.arg(default_days)::int) AS retention_days,
+        COALESCE(feeds.retention_max_posts, sqlc.arg(default_max_posts)::int) AS retention_max_posts
+    FROM posts
+    INNER JOIN feeds
+        ON posts.feed_id = feeds.id
+), deleted AS (
+    DELETE FROM posts
+    USING ranked
+    WHERE posts.id = ranked.id
+    AND (
+        (ranked.retention_days > 0 AND ranked.published_at < NOW() - make_interval(days => ranked.retention_days))
+        OR (ranked.retention_max_posts > 0 AND ranked.position > ranked.retention_max_posts)
+    )
+    AND NOT EXISTS (SELECT 1 FROM saved_posts WHERE saved_posts.post_id = posts.id)
+    AND NOT EXISTS (
+        SELECT 1 FROM feed_follows
+        WHERE feed_follows.feed_id = posts.feed_id
+        AND NOT EXISTS (
+            SELECT 1 FROM post_reads
+            WHERE post_reads.post_id = posts.id
+            AND post_reads.user_id = feed_follows.user_id
+        )
+    )
+    RETURNING posts.feed_id, COALESCE(posts.guid, posts.url) AS post_key
+), tombstones AS (
+    INSERT INTO pruned_posts (feed_id, post_key, last_seen_at)
+    SELECT feed_id, post_key, NOW() FROM deleted
+    ON CONFLICT DO NOTHING
+), expired AS (
+    DELETE FROM pruned_posts
+    WHERE last_seen_at < NOW() - make_interval(days => sqlc.arg(tombstone_days)::int)
+)
+SELECT COUNT(*) FROM deleted;
+
+-- name: CountPrunablePosts :many
+WITH ranked AS (
+    SELECT
+        posts.id,
+        posts.feed_id,
+        posts.published_at,
+        ROW_NUMBER() OVER (PARTITION BY posts.feed_id ORDER BY posts.published_at DESC, posts.id DESC) AS position,
+        COALESCE(feeds.retention_days, sqlc.arg(default_days)::int) AS retention_days,
+        COALESCE(feeds.retention_max_posts, sqlc.arg(default_max_posts)::int) AS retention_max_posts
+    FROM posts
+    INNER JOIN feeds
+        ON posts.feed_id = feeds.id
+)
+SELECT feeds.name, COUNT(*) AS prunable
+FROM ranked
+INNER JOIN feeds
+    ON ranked.feed_id = feeds.id
+WHERE (
+    (ranked.retention_days > 0 AND ranked.published_at < NOW() - make_interval(days => ranked.retention_days))
+    OR (ranked.retention_max_posts > 0 AND ranked.position > ranked.retention_max_posts)
+)
+AND NOT EXISTS (SELECT 1 FROM saved_posts WHERE saved_posts.post_id = ranked.id)
+AND NOT EXISTS (
+    SELECT 1 FROM feed_follows
+    WHERE feed_follows.feed_id = ranked.feed_id
+    AND NOT EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = ranked.id
+        AND post_reads.user_id = feed_follows.user_id
+    )
+)
+GROUP BY feeds.name
+ORDER BY feeds.name;
+
+-- name: SetFeedRetention :execrows
+UPDATE feeds
+SET retention_days = $2,
+retention_max_posts = $3,
+updated_at = NOW()
+WHERE url = $1;
@@ -0,0 +1,27 @@
+-- name: SavePost :exec
+INSERT INTO saved_posts (user_id, post_id, created_at, tags)
+VALUES (
+    $1,
+    $2,
+    NOW(),
+    $3
+    )
+    ON CONFLICT (user_id, post_id) DO UPDATE
+    SET tags = ARRAY(SELECT DISTINCT unnest(saved_posts.tags || EXCLUDED.tags));
+
+-- name: UnsavePost :execrows
+DELETE FROM saved_posts
+WHERE user_id = $1
+AND post_id = $2;
+
+-- name: GetSavedPostsForUser :many
+SELECT
+    posts.*,
+    saved_posts.tags,
+    saved_posts.created_at AS saved_at
+FROM saved_posts
+INNER JOIN posts
+    ON saved_posts.post_id = posts.id
+WHERE saved_posts.user_id = sqlc.arg(user_id)
+AND (sqlc.narg(tag)::text IS NULL OR sqlc.narg(tag) = ANY(saved_posts.tags))
+ORDER BY saved_posts.created_at DESC;
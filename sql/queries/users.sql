@@ -23,3 +23,15 @@ SELECT * FROM users;
 SELECT name FROM users
 WHERE id = $1
 LIMIT 1;
+
+-- name: SetUserAPIKey :one
+UPDATE users
+SET api_key_hash = $2,
+updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: GetUserByAPIKey :one
+SELECT * FROM users
+WHERE api_key_hash = $1
+LIMIT 1;
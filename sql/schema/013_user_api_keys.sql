@@ -0,0 +1,8 @@
+-- +goose Up
+-- only a sha256 of each key is stored, gator apikey shows the key once
+ALTER TABLE users
+ADD COLUMN api_key_hash TEXT UNIQUE;
+
+-- +goose Down
+ALTER TABLE users
+DROP COLUMN api_key_hash;
@@ -0,0 +1,49 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN guid TEXT,
+ADD COLUMN guid_is_permalink BOOLEAN,
+ADD COLUMN author TEXT,
+ADD COLUMN content TEXT;
+
+-- posts with a guid are unique per feed, the rest fall back to their url
+ALTER TABLE posts
+DROP CONSTRAINT posts_url_key;
+CREATE UNIQUE INDEX posts_feed_guid_key ON posts (feed_id, guid) WHERE guid IS NOT NULL;
+CREATE UNIQUE INDEX posts_url_without_guid_key ON posts (url) WHERE guid IS NULL;
+
+CREATE TABLE post_categories (
+    post_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    PRIMARY KEY (post_id, name),
+    CONSTRAINT fk_post_id FOREIGN KEY (post_id) REFERENCES posts (id) ON DELETE CASCADE
+);
+
+CREATE TABLE post_enclosures (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    post_id INTEGER NOT NULL,
+    url TEXT NOT NULL,
+    type TEXT,
+    length BIGINT,
+    UNIQUE (post_id, url),
+    CONSTRAINT fk_post_id FOREIGN KEY (post_id) REFERENCES posts (id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE post_enclosures;
+DROP TABLE post_categories;
+
+DROP INDEX posts_url_without_guid_key;
+DROP INDEX posts_feed_guid_key;
+-- the same url may now be stored once per feed, keep the oldest copy
+DELETE FROM posts a
+USING posts b
+WHERE a.url = b.url
+AND a.id > b.id;
+ALTER TABLE posts
+ADD CONSTRAINT posts_url_key UNIQUE (url);
+
+ALTER TABLE posts
+DROP COLUMN guid,
+DROP COLUMN guid_is_permalink,
+DROP COLUMN author,
+DROP COLUMN content;
@@ -0,0 +1,22 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN is_podcast BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE downloads (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    enclosure_id INTEGER NOT NULL UNIQUE,
+    path TEXT NOT NULL,
+    status TEXT NOT NULL,
+    bytes_downloaded BIGINT NOT NULL DEFAULT 0,
+    total_bytes BIGINT,
+    last_error TEXT,
+    CONSTRAINT fk_enclosure_id FOREIGN KEY (enclosure_id) REFERENCES post_enclosures (id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE downloads;
+
+ALTER TABLE feeds
+DROP COLUMN is_podcast;
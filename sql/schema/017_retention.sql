@@ -0,0 +1,21 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN retention_days INTEGER,
+ADD COLUMN retention_max_posts INTEGER;
+
+-- last_seen_at is when the feed last listed a pruned post, tombstones expire
+-- once it stops listing it
+CREATE TABLE pruned_posts (
+    feed_id INTEGER NOT NULL,
+    post_key TEXT NOT NULL,
+    last_seen_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (feed_id, post_key),
+    CONSTRAINT fk_feed_id FOREIGN KEY (feed_id) REFERENCES feeds (id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE pruned_posts;
+
+ALTER TABLE feeds
+DROP COLUMN retention_days,
+DROP COLUMN retention_max_posts;
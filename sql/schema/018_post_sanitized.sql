@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN safe_html TEXT,
+ADD COLUMN excerpt TEXT;
+
+-- +goose Down
+ALTER TABLE posts
+DROP COLUMN safe_html,
+DROP COLUMN excerpt;
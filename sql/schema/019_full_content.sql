@@ -0,0 +1,24 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN fetch_full_content BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE posts
+ADD COLUMN full_content TEXT;
+
+CREATE TABLE full_content_queue (
+    post_id INTEGER PRIMARY KEY,
+    queued_at TIMESTAMP NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL,
+    last_error TEXT,
+    CONSTRAINT fk_post_id FOREIGN KEY (post_id) REFERENCES posts (id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE full_content_queue;
+
+ALTER TABLE posts
+DROP COLUMN full_content;
+
+ALTER TABLE feeds
+DROP COLUMN fetch_full_content;
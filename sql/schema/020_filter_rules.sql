@@ -0,0 +1,29 @@
+-- +goose Up
+CREATE TABLE filter_rules (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    user_id uuid NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('mute', 'highlight')),
+    field TEXT NOT NULL CHECK (field IN ('title', 'description', 'any')),
+    pattern TEXT NOT NULL,
+    feed_id INTEGER,
+    CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_feed_id FOREIGN KEY (feed_id) REFERENCES feeds (id) ON DELETE CASCADE
+);
+
+CREATE INDEX filter_rules_user_id_idx ON filter_rules (user_id);
+
+-- +goose StatementBegin
+CREATE FUNCTION filter_rule_matches(field TEXT, pattern TEXT, title TEXT, description TEXT)
+RETURNS BOOLEAN
+LANGUAGE SQL IMMUTABLE
+AS $$
+    SELECT (field IN ('title', 'any') AND strpos(lower(title), lower(pattern)) > 0)
+        OR (field IN ('description', 'any') AND strpos(lower(description), lower(pattern)) > 0)
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION filter_rule_matches;
+
+DROP TABLE filter_rules;
@@ -0,0 +1,29 @@
+-- +goose Up
+-- Match rules against the description's text rather than its markup, so
+-- "description~AT&T" finds "AT&amp;T" and attributes don't count as words.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION filter_rule_matches(field TEXT, pattern TEXT, title TEXT, description TEXT)
+RETURNS BOOLEAN
+LANGUAGE SQL IMMUTABLE
+AS $$
+    SELECT (field IN ('title', 'any') AND strpos(lower(title), lower(pattern)) > 0)
+        OR (field IN ('description', 'any') AND strpos(lower(
+            regexp_replace(
+                replace(replace(replace(replace(replace(replace(
+                    regexp_replace(description, '<[^>]*>', ' ', 'g'),
+                    '&nbsp;', ' '), '&quot;', '"'), '&#39;', ''''), '&lt;', '<'), '&gt;', '>'), '&amp;', '&'),
+                '\s+', ' ', 'g')
+        ), lower(pattern)) > 0)
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION filter_rule_matches(field TEXT, pattern TEXT, title TEXT, description TEXT)
+RETURNS BOOLEAN
+LANGUAGE SQL IMMUTABLE
+AS $$
+    SELECT (field IN ('title', 'any') AND strpos(lower(title), lower(pattern)) > 0)
+        OR (field IN ('description', 'any') AND strpos(lower(description), lower(pattern)) > 0)
+$$;
+-- +goose StatementEnd
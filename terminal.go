@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// terminal switches the controlling terminal in and out of raw mode with
+// stty, so the tui doesn't need anything outside the standard library.
+type terminal struct {
+	saved     string
+	closeOnce sync.Once
+	signals   chan os.Signal
+
+	// resized receives a value whenever the cached size changes.
+	resized chan struct{}
+
+	mu   sync.Mutex
+	cols int
+	rows int
+}
+
+func openTerminal() (*terminal, error) {
+	saved, err := stty("-g")
+	if err != nil {
+		return nil, fmt.Errorf("tui needs an interactive terminal: %w", err)
+	}
+	if _, err := stty("raw", "-echo"); err != nil {
+		return nil, err
+	}
+
+	t := &terminal{
+		saved:   strings.TrimSpace(saved),
+		signals: make(chan os.Signal, 1),
+		resized: make(chan struct{}, 1),
+	}
+	t.readSize()
+
+	// Raw mode turns ctrl-c into a key, but kill or a closed terminal still
+	// sends a signal and the shell must not be left in raw mode.
+	signal.Notify(t.signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	if resizeSignal != nil {
+		signal.Notify(t.signals, resizeSignal)
+	}
+	go t.handleSignals()
+
+	// Alternate screen, hidden cursor.
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	return t, nil
+}
+
+func (t *terminal) close() {
+	t.closeOnce.Do(func() {
+		signal.Stop(t.signals)
+		fmt.Print("\x1b[?25h\x1b[?1049l")
+		stty(t.saved)
+	})
+}
+
+func (t *terminal) handleSignals() {
+	for sig := range t.signals {
+		if sig == resizeSignal {
+			t.readSize()
+			select {
+			case t.resized <- struct{}{}:
+			default:
+			}
+			continue
+		}
+		t.close()
+		os.Exit(1)
+	}
+}
+
+// size returns the number of columns and rows as of the last resize.
+func (t *terminal) size() (int, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.cols, t.rows
+}
+
+// readSize asks stty for the size, falling back to 80x24.
+func (t *terminal) readSize() {
+	cols, rows := 80, 24
+	if out, err := stty("size"); err == nil {
+		var r, c int
+		if _, err := fmt.Sscan(out, &r, &c); err == nil && r > 0 && c > 0 {
+			cols, rows = c, r
+		}
+	}
+
+	t.mu.Lock()
+	t.cols, t.rows = cols, rows
+	t.mu.Unlock()
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return string(out), err
+}
+
+const (
+	keyUp    = "up"
+	keyDown  = "down"
+	keyLeft  = "left"
+	keyRight = "right"
+	keyPgUp  = "pgup"
+	keyPgDn  = "pgdn"
+	keyTab   = "tab"
+	keyBack  = "backtab"
+	keyEnter = "enter"
+	keyQuit  = "quit"
+)
+
+// readKeys turns raw stdin bytes into key names, decoding the escape
+// sequences sent for arrow and paging keys. Other keys are sent as typed.
+func readKeys(keys chan<- string) {
+	buf := make([]byte, 64)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			close(keys)
+			return
+		}
+		for in := string(buf[:n]); in != ""; {
+			key, size := decodeKey(in)
+			keys <- key
+			in = in[size:]
+		}
+	}
+}
+
+func decodeKey(in string) (string, int) {
+	sequences := []struct{ seq, key string }{
+		{"\x1b[A", keyUp}, {"\x1b[B", keyDown}, {"\x1b[C", keyRight}, {"\x1b[D", keyLeft},
+		{"\x1b[5~", keyPgUp}, {"\x1b[6~", keyPgDn}, {"\x1b[Z", keyBack},
+	}
+	for _, s := range sequences {
+		if strings.HasPrefix(in, s.seq) {
+			return s.key, len(s.seq)
+		}
+	}
+
+	switch in[0] {
+	case '\t':
+		return keyTab, 1
+	case '\r', '\n':
+		return keyEnter, 1
+	case 3, 4: // ctrl-c, ctrl-d
+		return keyQuit, 1
+	}
+	return in[:1], 1
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// resizeSignal is nil where there is no SIGWINCH, the size is then only read
+// when the tui starts.
+var resizeSignal os.Signal
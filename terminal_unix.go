@@ -0,0 +1,10 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+var resizeSignal os.Signal = syscall.SIGWINCH
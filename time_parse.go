@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// layouts are tried after normalizeTimestamp has dropped the weekday and turned
+// named zones into numeric offsets
+var timeLayouts = []string{
+	time.RFC3339,
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 -07:00",
+	"2 Jan 2006 15:04 -0700",
+	"2 Jan 2006 15:04:05",
+	"2 Jan 2006 15:04",
+	"2 Jan 06 15:04:05 -0700",
+	"2 Jan 06 15:04 -0700",
+	"2 Jan 06 15:04:05",
+	"2 January 2006 15:04:05 -0700",
+	"2 January 2006 15:04 -0700",
+	"2 Jan 2006",
+	"2 January 2006",
+	"Jan 2 2006 15:04:05 -0700",
+	"Jan 2 15:04:05 2006",
+	"Jan 2 15:04:05 -0700 2006",
+	"January 2, 2006 15:04:05 -0700",
+	"January 2, 2006 15:04",
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05 -07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+}
+
+// zoneOffsets covers the abbreviations feeds use in practice. time.Parse would
+// otherwise give unknown abbreviations like EDT a zero offset.
+var zoneOffsets = map[string]string{
+	"UT":   "+0000",
+	"UTC":  "+0000",
+	"GMT":  "+0000",
+	"Z":    "+0000",
+	"EST":  "-0500",
+	"EDT":  "-0400",
+	"CST":  "-0600",
+	"CDT":  "-0500",
+	"MST":  "-0700",
+	"MDT":  "-0600",
+	"PST":  "-0800",
+	"PDT":  "-0700",
+	"AKST": "-0900",
+	"AKDT": "-0800",
+	"HST":  "-1000",
+	"BST":  "+0100",
+	"IST":  "+0530",
+	"WET":  "+0000",
+	"WEST": "+0100",
+	"CET":  "+0100",
+	"CEST": "+0200",
+	"EET":  "+0200",
+	"EEST": "+0300",
+	"MSK":  "+0300",
+	"JST":  "+0900",
+	"KST":  "+0900",
+	"AEST": "+1000",
+	"AEDT": "+1100",
+	"NZST": "+1200",
+	"NZDT": "+1300",
+}
+
+func parseTime(timestamp string) (time.Time, error) {
+	normalized := normalizeTimestamp(timestamp)
+	if normalized == "" {
+		return time.Time{}, fmt.Errorf("missing timestamp")
+	}
+
+	if t, ok := parseTimeLayouts(normalized); ok {
+		return t, nil
+	}
+
+	// last resort: drop a zone we don't know and assume UTC
+	if i := strings.LastIndex(normalized, " "); i > 0 && isLetters(normalized[i+1:]) {
+		if t, ok := parseTimeLayouts(normalized[:i]); ok {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognized time format: %s", timestamp)
+}
+
+func parseTimeLayouts(timestamp string) (time.Time, bool) {
+	for _, tl := range timeLayouts {
+		if t, err := time.Parse(tl, timestamp); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func normalizeTimestamp(timestamp string) string {
+	fields := strings.Fields(timestamp)
+
+	// the weekday is often missing or wrong, so it is never used
+	if len(fields) > 0 && isWeekday(strings.TrimSuffix(fields[0], ",")) {
+		fields = fields[1:]
+	}
+
+	for i, field := range fields {
+		if offset, ok := zoneOffsets[strings.ToUpper(field)]; ok && i > 0 {
+			fields[i] = offset
+		}
+		if strings.EqualFold(field, "Sept") {
+			fields[i] = "Sep"
+		}
+	}
+
+	return strings.Join(fields, " ")
+}
+
+// feedFallbackTime is used for items without a usable date so they don't sort
+// as 0001-01-01: the feed's own build date if it has one, otherwise now
+func feedFallbackTime(feed *RSSFeed) time.Time {
+	for _, timestamp := range []string{feed.Channel.LastBuildDate, feed.Channel.PubDate} {
+		if t, err := parseTime(timestamp); err == nil {
+			return t
+		}
+	}
+	return time.Now()
+}
+
+func isWeekday(s string) bool {
+	if len(s) < 3 {
+		return false
+	}
+	s = strings.ToLower(s)
+	for _, day := range []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"} {
+		if strings.HasPrefix(day, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func isLetters(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		// RFC 822 and 1123 as used by RSS, with and without the weekday
+		{"Mon, 02 Jan 2006 15:04:05 -0700", "2006-01-02T22:04:05Z"},
+		{"02 Jan 2006 15:04:05 -0700", "2006-01-02T22:04:05Z"},
+		{"Mon, 2 Jan 2006 15:04:05 +05:30", "2006-01-02T09:34:05Z"},
+		{"Mon, 02 Jan 2006 15:04 -0700", "2006-01-02T22:04:00Z"},
+		{"Mon, 02 Jan 2006 15:04:05", "2006-01-02T15:04:05Z"},
+		{"Mon, 02 Jan 2006 15:04", "2006-01-02T15:04:00Z"},
+		{"Mon, 02 Jan 06 15:04:05 -0700", "2006-01-02T22:04:05Z"},
+		{"Mon, 02 Jan 06 15:04 -0700", "2006-01-02T22:04:00Z"},
+		{"02 Jan 06 15:04:05", "2006-01-02T15:04:05Z"},
+		{"Monday, 2 January 2006 15:04:05 -0700", "2006-01-02T22:04:05Z"},
+		{"2 January 2006 15:04 -0700", "2006-01-02T22:04:00Z"},
+		{"2 Jan 2006", "2006-01-02T00:00:00Z"},
+		{"2 January 2006", "2006-01-02T00:00:00Z"},
+
+		// a wrong weekday is ignored
+		{"Fri, 02 Jan 2006 15:04:05 +0000", "2006-01-02T15:04:05Z"},
+
+		// named zones
+		{"Mon, 02 Jan 2006 15:04:05 GMT", "2006-01-02T15:04:05Z"},
+		{"Mon, 02 Jan 2006 15:04:05 UT", "2006-01-02T15:04:05Z"},
+		{"Mon, 02 Jan 2006 15:04:05 gmt", "2006-01-02T15:04:05Z"},
+		{"Tue, 10 Jun 2003 04:00:00 EDT", "2003-06-10T08:00:00Z"},
+		{"Wed, 02 Oct 2002 13:00:00 PST", "2002-10-02T21:00:00Z"},
+		{"Thu, 05 Sep 2024 10:00:00 CEST", "2024-09-05T08:00:00Z"},
+		{"Thu, 05 Sep 2024 10:00:00 IST", "2024-09-05T04:30:00Z"},
+		{"Thu, 05 Sep 2024 10:00:00 AEDT", "2024-09-04T23:00:00Z"},
+		{"Thursday, 5 Sept 2024 10:00:00 GMT", "2024-09-05T10:00:00Z"},
+
+		// month first
+		{"Jan 2 2006 15:04:05 -0700", "2006-01-02T22:04:05Z"},
+		{"Mon Jan 2 15:04:05 2006", "2006-01-02T15:04:05Z"},
+		{"Mon Jan 2 15:04:05 MST 2006", "2006-01-02T22:04:05Z"},
+		{"January 2, 2006 15:04:05 -0700", "2006-01-02T22:04:05Z"},
+		{"January 2, 2006 15:04", "2006-01-02T15:04:00Z"},
+		{"January 2, 2006", "2006-01-02T00:00:00Z"},
+		{"Jan 2, 2006", "2006-01-02T00:00:00Z"},
+
+		// ISO 8601 and friends
+		{"2006-01-02T15:04:05Z", "2006-01-02T15:04:05Z"},
+		{"2006-01-02T15:04:05+02:00", "2006-01-02T13:04:05Z"},
+		{"2006-01-02T15:04:05.123Z", "2006-01-02T15:04:05.123Z"},
+		{"2006-01-02T15:04:05+0200", "2006-01-02T13:04:05Z"},
+		{"2006-01-02T15:04Z", "2006-01-02T15:04:00Z"},
+		{"2006-01-02T15:04-05:00", "2006-01-02T20:04:00Z"},
+		{"2006-01-02T15:04:05", "2006-01-02T15:04:05Z"},
+		{"2006-01-02T15:04", "2006-01-02T15:04:00Z"},
+		{"2006-01-02 15:04:05Z", "2006-01-02T15:04:05Z"},
+		{"2006-01-02 15:04:05 -0700", "2006-01-02T22:04:05Z"},
+		{"2006-01-02 15:04:05 +02:00", "2006-01-02T13:04:05Z"},
+		{"2006-01-02 15:04:05 UTC", "2006-01-02T15:04:05Z"},
+		{"2006-01-02 15:04:05", "2006-01-02T15:04:05Z"},
+		{"2006-01-02 15:04", "2006-01-02T15:04:00Z"},
+		{"2006-01-02", "2006-01-02T00:00:00Z"},
+		{"2006/01/02 15:04:05", "2006-01-02T15:04:05Z"},
+		{"2006/01/02", "2006-01-02T00:00:00Z"},
+
+		// surrounding and repeated whitespace
+		{"  Mon,  02 Jan 2006\n15:04:05 GMT ", "2006-01-02T15:04:05Z"},
+
+		// unknown zones fall back to UTC
+		{"Mon, 02 Jan 2006 15:04:05 XYZ", "2006-01-02T15:04:05Z"},
+		{"2006-01-02 15:04:05 Foo", "2006-01-02T15:04:05Z"},
+	}
+
+	for _, tt := range tests {
+		got, err := parseTime(tt.input)
+		if err != nil {
+			t.Errorf("parseTime(%q) returned error: %v", tt.input, err)
+			continue
+		}
+		if got := got.UTC().Format(time.RFC3339Nano); got != tt.want {
+			t.Errorf("parseTime(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseTimeErrors(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"   ",
+		"Mon,",
+		"not a date",
+		"2006-13-45",
+		"32 Jan 2006",
+		"Mon, 02 Jan 2006 25:04:05 GMT",
+		"Mon, 02 Jan 2006 15:04:05 +9999 extra",
+	} {
+		if got, err := parseTime(input); err == nil {
+			t.Errorf("parseTime(%q) = %v, want error", input, got)
+		}
+	}
+}
+
+func TestFeedFallbackTime(t *testing.T) {
+	feed := &RSSFeed{}
+	feed.Channel.LastBuildDate = "not a date"
+	feed.Channel.PubDate = "Mon, 02 Jan 2006 15:04:05 GMT"
+	if got := feedFallbackTime(feed); !got.Equal(time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)) {
+		t.Errorf("feedFallbackTime used %v, want the channel pubDate", got)
+	}
+
+	feed.Channel.PubDate = ""
+	before := time.Now()
+	if got := feedFallbackTime(feed); got.Before(before) || got.After(time.Now()) {
+		t.Errorf("feedFallbackTime = %v, want the current time", got)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import "strings"
+
+// maxDiffCells bounds the LCS table so a huge rewrite can't eat all the memory.
+const maxDiffCells = 4_000_000
+
+// wordDiff compares two texts word by word and marks removed words as [-word-]
+// and added words as {+word+}, like git diff --word-diff.
+func wordDiff(before, after string) string {
+	a := strings.Fields(before)
+	b := strings.Fields(after)
+
+	if len(a)*len(b) > maxDiffCells {
+		return joinChange("[-", a, "-]") + " " + joinChange("{+", b, "+}")
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out []string
+	var removed, added []string
+	flush := func() {
+		if len(removed) > 0 {
+			out = append(out, joinChange("[-", removed, "-]"))
+		}
+		if len(added) > 0 {
+			out = append(out, joinChange("{+", added, "+}"))
+		}
+		removed, added = nil, nil
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			flush()
+			out = append(out, a[i])
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			removed = append(removed, a[i])
+			i++
+		default:
+			added = append(added, b[j])
+			j++
+		}
+	}
+	flush()
+
+	return strings.Join(out, " ")
+}
+
+func joinChange(open string, words []string, close string) string {
+	if len(words) == 0 {
+		return ""
+	}
+	return open + strings.Join(words, " ") + close
+}